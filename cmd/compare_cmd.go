@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"tpcds_benchmark/pkg/report"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// exitRegressed - код выхода compare при регрессиях или новых ошибках; 1 - ошибка самой
+// команды (флаги, чтение наборов), 0 - регрессий нет
+const exitRegressed = 2
+
+// compareCommand сравнивает два набора результатов; код выхода exitRegressed
+// при регрессиях и новых ошибках
+func compareCommand(args []string) {
+	// не ExitOnError: flag завершает с кодом 2, который занят под регрессии
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+
+	var hf historyFlags
+	hf.register(fs)
+
+	opts := report.CompareOptions{}
+	fs.Float64Var(&opts.Threshold, "threshold", 0.1, "относительное изменение медианы для регрессии или улучшения (0.1 = 10%)")
+	fs.Float64Var(&opts.Confidence, "confidence", 0.95, "уровень доверительного интервала")
+	fs.IntVar(&opts.Iterations, "iterations", 2000, "итераций бутстрепа")
+	fs.Int64Var(&opts.Seed, "seed", 1, "seed бутстрепа")
+	format := fs.String("format", "table", "формат: "+strings.Join(report.CompareFormats, ", "))
+	output := fs.String("o", "", "файл сравнения (по умолчанию stdout)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	}
+
+	if fs.NArg() != 2 {
+		log.Fatalf("использование: compare [флаги] <базовый> <кандидат>; " +
+			"набор - файл результатов, s3:<ключ> относительно prefix или run:<id|имя> из истории; " +
+			"код выхода 2 - регрессии или новые ошибки, 1 - ошибка сравнения")
+	}
+
+	if opts.Threshold < 0 {
+		log.Fatalf("threshold не может быть отрицательным")
+	}
+
+	if opts.Confidence <= 0 || opts.Confidence >= 1 {
+		log.Fatalf("confidence должен быть между 0 и 1")
+	}
+
+	if opts.Iterations <= 0 {
+		log.Fatalf("iterations должен быть больше 0")
+	}
+
+	baseName, baseline, err := loadRef(&hf, fs.Arg(0))
+	if err != nil {
+		log.Fatalf("базовый набор: %v", err)
+	}
+
+	candName, candidate, err := loadRef(&hf, fs.Arg(1))
+	if err != nil {
+		log.Fatalf("кандидат: %v", err)
+	}
+
+	comparison := report.Compare(baseName, baseline, candName, candidate, opts)
+
+	w := os.Stdout
+	if *output != "" {
+		w, err = os.Create(*output)
+		if err != nil {
+			log.Fatalf("ошибка при создании файла: %v", err)
+		}
+	}
+
+	if err := report.WriteComparison(w, comparison, *format); err != nil {
+		log.Fatalf("ошибка вывода сравнения: %v", err)
+	}
+
+	if *output != "" {
+		w.Close()
+	}
+
+	if comparison.Regressed() {
+		log.Printf("обнаружены регрессии или новые ошибки")
+		os.Exit(exitRegressed)
+	}
+}
+
+// loadRef читает набор результатов: run:<id|имя> из истории, s3:<ключ> или локальный файл
+func loadRef(hf *historyFlags, ref string) (string, []storage.BenchmarkResult, error) {
+	if id, ok := strings.CutPrefix(ref, "run:"); ok {
+		return loadRun(hf, id)
+	}
+
+	if key, ok := strings.CutPrefix(ref, "s3:"); ok {
+		return loadS3(hf, key)
+	}
+
+	results, err := storage.ReadResults(ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Base(ref), storage.LatestResults(results), nil
+}
+
+func loadS3(hf *historyFlags, key string) (string, []storage.BenchmarkResult, error) {
+	cfg, err := hf.load()
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка при чтении конфига: %w", err)
+	}
+
+	if cfg.S3 == nil || !cfg.S3.Enabled {
+		return "", nil, fmt.Errorf("s3_config не включен в конфиге")
+	}
+
+	s3, err := storage.NewS3Storage(cfg.S3, cfg.CertPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка при создании s3 клиента: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "tpcds-compare-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, path.Base(key))
+	if err := s3.Download(key, local); err != nil {
+		return "", nil, err
+	}
+
+	results, err := storage.ReadResults(local)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "s3:" + key, storage.LatestResults(results), nil
+}
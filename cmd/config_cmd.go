@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+func configCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatalf("использование: config <print|validate> [флаги]")
+	}
+
+	sub, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("config "+sub, flag.ExitOnError)
+
+	var cf configFlags
+	cf.register(fs)
+	fs.Parse(args)
+
+	cfg, err := cf.load()
+	if err != nil {
+		log.Fatalf("ошибка при чтении конфига: %v", err)
+	}
+
+	switch sub {
+	case "print":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(cfg.Redacted()); err != nil {
+			log.Fatalf("ошибка вывода конфига: %v", err)
+		}
+
+		enc.Close()
+
+	case "validate":
+		fmt.Printf("конфигурация корректна: %d хранилищ\n", len(cfg.Warehouses))
+
+	default:
+		log.Fatalf("неизвестная подкоманда config: %s", sub)
+	}
+}
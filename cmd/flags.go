@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"tpcds_benchmark/pkg/config"
+)
+
+const defaultConfigPath = "config/config.yaml"
+
+// stringList - повторяемый флаг, значения также можно перечислить через запятую
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*s = append(*s, item)
+		}
+	}
+	return nil
+}
+
+type configFlags struct {
+	path     string
+	overlays stringList
+	profiles stringList
+	suite    string
+}
+
+func (cf *configFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&cf.path, "config", defaultConfigPath, "базовый файл конфигурации")
+	fs.Var(&cf.overlays, "overlay", "файл-оверлей поверх базового конфига (можно несколько)")
+	fs.Var(&cf.profiles, "profile", "профиль из секции profiles (можно несколько)")
+	fs.StringVar(&cf.suite, "suite", "", "набор запросов из секции suites")
+}
+
+func (cf *configFlags) load() (*config.Config, error) {
+	return config.Load(config.LoadOptions{
+		Path:     cf.path,
+		Overlays: cf.overlays,
+		Profiles: cf.profiles,
+		Suite:    cf.suite,
+	})
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+	"tpcds_benchmark/pkg/history"
+)
+
+// historyFlags - путь к базе истории: явный -db или history.path из конфига
+type historyFlags struct {
+	configFlags
+	db string
+}
+
+func (hf *historyFlags) register(fs *flag.FlagSet) {
+	hf.configFlags.register(fs)
+	fs.StringVar(&hf.db, "db", "", "файл истории запусков (по умолчанию history.path или results_path/history.db)")
+}
+
+func (hf *historyFlags) open() (*history.Store, error) {
+	path := hf.db
+
+	if path == "" {
+		cfg, err := hf.load()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении конфига: %w", err)
+		}
+
+		path = filepath.Join(cfg.ResultsPath, "history.db")
+		if cfg.History != nil && cfg.History.Path != "" {
+			path = cfg.History.Path
+		}
+	}
+
+	return history.Open(path)
+}
+
+// importCommand загружает в историю csv файлы прошлых запусков
+func importCommand(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+
+	var hf historyFlags
+	hf.register(fs)
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		log.Fatalf("использование: import [флаги] <results.csv>...")
+	}
+
+	store, err := hf.open()
+	if err != nil {
+		log.Fatalf("ошибка открытия истории: %v", err)
+	}
+	defer store.Close()
+
+	failed := 0
+
+	for _, path := range fs.Args() {
+		run, err := store.ImportCSV(path)
+		if err != nil {
+			log.Printf("ERROR: %s: %v", path, err)
+			failed++
+			continue
+		}
+
+		log.Printf("импортирован запуск %d (%s): %d результатов", run.ID, run.Name, run.Results)
+	}
+
+	if failed > 0 {
+		store.Close()
+		log.Fatalf("не импортировано файлов: %d", failed)
+	}
+}
+
+// historyCommand выводит запуски из истории
+func historyCommand(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+
+	var hf historyFlags
+	hf.register(fs)
+	fs.Parse(args)
+
+	store, err := hf.open()
+	if err != nil {
+		log.Fatalf("ошибка открытия истории: %v", err)
+	}
+	defer store.Close()
+
+	runs, err := store.Runs()
+	if err != nil {
+		log.Fatalf("ошибка чтения истории: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tЗАПУСК\tСТАТУС\tНАЧАЛО\tДЛИТЕЛЬНОСТЬ\tSUITE\tРЕВИЗИЯ\tРЕЗУЛЬТАТОВ")
+
+	for _, run := range runs {
+		duration := ""
+		if !run.StartedAt.IsZero() && !run.FinishedAt.IsZero() {
+			duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			run.ID,
+			run.Name,
+			run.Status,
+			run.StartedAt.Local().Format(time.DateTime),
+			duration,
+			run.Suite,
+			shortRevision(run.GitRevision),
+			run.Results,
+		)
+	}
+
+	w.Flush()
+}
+
+func shortRevision(revision string) string {
+	if len(revision) > 12 {
+		return revision[:12]
+	}
+
+	return revision
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 	"tpcds_benchmark/pkg/config"
@@ -19,7 +21,39 @@ import (
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	cfg, err := config.LoadConfig("config/config.yaml")
+	command, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "run":
+		runBenchmark(args)
+	case "config":
+		configCommand(args)
+	case "import":
+		importCommand(args)
+	case "history":
+		historyCommand(args)
+	case "report":
+		reportCommand(args)
+	case "compare":
+		compareCommand(args)
+	default:
+		log.Fatalf("неизвестная команда: %s (доступны: run, config, import, history, report, compare)", command)
+	}
+}
+
+func runBenchmark(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+
+	var cf configFlags
+	cf.register(fs)
+
+	resumePath := fs.String("resume", "", "продолжить прерванный запуск: дописать недостающие задачи в этот файл результатов")
+	fs.Parse(args)
+
+	cfg, err := cf.load()
 	if err != nil {
 		log.Fatalf("ошибка при чтении конфига: %v", err)
 	}
@@ -70,7 +104,7 @@ func main() {
 		}
 	}
 
-	benchRunner, err := runner.NewBenchmarkRunner(cfg, connMgr, s3, filename)
+	benchRunner, err := runner.NewBenchmarkRunner(cfg, connMgr, s3, filename, *resumePath)
 	if err != nil {
 		log.Fatalf("ошибка создания бенчмарка: %v", err)
 	}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"tpcds_benchmark/pkg/report"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// reportCommand строит сводный отчет по файлам результатов или запуску из истории
+func reportCommand(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+
+	var hf historyFlags
+	hf.register(fs)
+
+	format := fs.String("format", "table", "формат отчета: "+strings.Join(report.Formats, ", "))
+	output := fs.String("o", "", "файл отчета (по умолчанию stdout)")
+	runRef := fs.String("run", "", "запуск из истории (id или имя) вместо файлов результатов")
+	matrix := fs.Bool("matrix", false, "матрица запрос × хранилище: медианы, победитель и ускорение (форматы: "+strings.Join(report.MatrixFormats, ", ")+")")
+	baseline := fs.String("baseline", "", "базовое хранилище для ускорения в матрице (по умолчанию первое)")
+	fs.Parse(args)
+
+	if *runRef == "" && fs.NArg() == 0 {
+		log.Fatalf("использование: report [флаги] <results.csv|.jsonl|.parquet>... или report -run <id>")
+	}
+
+	source, results, err := loadResults(&hf, *runRef, fs.Args())
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	summary := report.Summarize(source, results)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("ошибка при создании файла: %v", err)
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	if *matrix {
+		m, err := report.BuildMatrix(summary, *baseline)
+		if err != nil {
+			log.Fatalf("ошибка построения матрицы: %v", err)
+		}
+
+		if err := report.WriteMatrix(w, m, *format); err != nil {
+			log.Fatalf("ошибка вывода матрицы: %v", err)
+		}
+
+		return
+	}
+
+	if err := report.Write(w, summary, *format); err != nil {
+		log.Fatalf("ошибка вывода отчета: %v", err)
+	}
+}
+
+// loadResults читает результаты запуска из истории или объединяет файлы результатов;
+// в каждом файле остается последняя строка задачи (после -resume)
+func loadResults(hf *historyFlags, runRef string, paths []string) (string, []storage.BenchmarkResult, error) {
+	if runRef != "" {
+		return loadRun(hf, runRef)
+	}
+
+	var (
+		names   []string
+		results []storage.BenchmarkResult
+	)
+
+	for _, path := range paths {
+		loaded, err := storage.ReadResults(path)
+		if err != nil {
+			return "", nil, err
+		}
+
+		names = append(names, filepath.Base(path))
+		results = append(results, storage.LatestResults(loaded)...)
+	}
+
+	return strings.Join(names, ", "), results, nil
+}
+
+// loadRun читает результаты запуска из истории по id или имени
+func loadRun(hf *historyFlags, ref string) (string, []storage.BenchmarkResult, error) {
+	store, err := hf.open()
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка открытия истории: %w", err)
+	}
+	defer store.Close()
+
+	run, err := store.FindRun(ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	results, err := store.Results(run.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка чтения истории: %w", err)
+	}
+
+	return run.Name, storage.LatestResults(results), nil
+}
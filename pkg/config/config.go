@@ -1,8 +1,9 @@
 package config
 
 import (
-	"fmt"
-	"os"
+	"log"
+	"math"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,6 +21,260 @@ type Config struct {
 	ConnectionRetries int               `yaml:"connection_retries"`
 	RetryDelay        string            `yaml:"retry_delay"`
 	S3                *S3Config         `yaml:"s3_config"`
+
+	// Куда пишутся результаты, по умолчанию один csv в results_path
+	Sinks []SinkConfig `yaml:"sinks"`
+
+	// Локальная история запусков в sqlite для отчетов и сравнений
+	History *HistoryConfig `yaml:"history"`
+
+	// Именованные наборы запросов; выбранный suite заменяет queries_path
+	Suites map[string]SuiteConfig `yaml:"suites"`
+	Suite  string                 `yaml:"suite"`
+
+	// Группы хранилищ (parallel_group) выполняются одновременно,
+	// хранилища внутри одной группы - последовательно
+	ParallelWarehouses bool `yaml:"parallel_warehouses"`
+
+	// Порядок выполнения: warehouse (по умолчанию) - хранилище целиком за другим,
+	// interleaved - каждый запрос по очереди на всех хранилищах, random - перемешанный по seed
+	ExecutionOrder string `yaml:"execution_order"`
+	OrderSeed      int64  `yaml:"order_seed"`
+
+	// Повторы неудачных запросов по классу ошибки:
+	// timeout, cancelled, syntax, resource, admission, connection, wrong_result, unknown
+	RetryPolicies map[string]RetryPolicy `yaml:"retry_policies"`
+
+	// Прекращает запуск запроса на хранилище после серии безнадежных ошибок
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker"`
+
+	// Бюджет времени на весь запуск: после него новые запросы не запускаются,
+	// а через deadline_grace выполняющиеся запросы отменяются
+	MaxTotalDuration string `yaml:"max_total_duration"`
+	DeadlineGrace    string `yaml:"deadline_grace"`
+
+	// Открытая модель нагрузки: запросы поступают с заданной частотой,
+	// независимо от завершения предыдущих
+	OpenLoop *OpenLoopConfig `yaml:"open_loop"`
+
+	// Ступенчатая нагрузка: concurrency растет по шагам до точки насыщения
+	StepLoad *StepLoadConfig `yaml:"step_load"`
+
+	// Смешанная нагрузка: несколько классов запросов одновременно
+	Scenario *ScenarioConfig `yaml:"scenario"`
+
+	// Шаблон имени схемы по умолчанию для всех хранилищ
+	SchemaTemplate string `yaml:"schema_template"`
+
+	root    *yaml.Node
+	sources sources
+}
+
+const (
+	OrderWarehouse   = "warehouse"
+	OrderInterleaved = "interleaved"
+	OrderRandom      = "random"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int    `yaml:"max_attempts"`
+	Backoff     string `yaml:"backoff"`
+	MaxBackoff  string `yaml:"max_backoff"`
+}
+
+// Delay - пауза перед попыткой attempt+1: backoff * 2^(attempt-1), не больше max_backoff.
+// Удвоение останавливается на max_backoff или до переполнения time.Duration
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	backoff, _ := time.ParseDuration(p.Backoff)
+	maxBackoff, err := time.ParseDuration(p.MaxBackoff)
+	if err != nil {
+		maxBackoff = math.MaxInt64
+	}
+
+	delay := backoff
+	for i := 1; i < attempt && delay > 0 && delay < maxBackoff; i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+			break
+		}
+
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
+
+// RetryPolicy возвращает политику для класса ошибки, без политики - одна попытка
+func (c *Config) RetryPolicy(errorClass string) RetryPolicy {
+	if policy, ok := c.RetryPolicies[errorClass]; ok {
+		return policy
+	}
+
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+type CircuitBreakerConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Сколько ошибок подряд для одного запроса открывают breaker
+	Threshold int `yaml:"threshold"`
+
+	// Классы ошибок, которые считаются, по умолчанию timeout и syntax
+	ErrorClasses []string `yaml:"error_classes"`
+}
+
+const (
+	ArrivalConstant = "constant"
+	ArrivalPoisson  = "poisson"
+)
+
+type StepLoadConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Явный список шагов concurrency, иначе 1, 2, 4 ... до max_concurrency
+	Steps          []int `yaml:"steps"`
+	MaxConcurrency int   `yaml:"max_concurrency"`
+
+	// Шаг длится step_duration или step_rounds проходов по всем запросам на каждом потоке
+	StepDuration string `yaml:"step_duration"`
+	StepRounds   int    `yaml:"step_rounds"`
+
+	// Остановка после шага, на котором p95 или доля ошибок превысили порог
+	MaxP95       string  `yaml:"max_p95"`
+	MaxErrorRate float64 `yaml:"max_error_rate"`
+}
+
+// MaxStep - наибольший шаг concurrency (число соединений на хранилище)
+func (sl *StepLoadConfig) MaxStep() int {
+	if len(sl.Steps) == 0 {
+		return 0
+	}
+
+	return sl.Steps[len(sl.Steps)-1]
+}
+
+type HistoryConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Файл базы, по умолчанию results_path/history.db
+	Path string `yaml:"path"`
+}
+
+type OpenLoopConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Запросов в минуту
+	ArrivalRate float64 `yaml:"arrival_rate"`
+
+	// constant - равные интервалы, poisson - экспоненциальные интервалы
+	Distribution string `yaml:"distribution"`
+
+	// Максимум одновременно выполняющихся запросов (= число соединений)
+	MaxOutstanding int `yaml:"max_outstanding"`
+
+	// Генерация останавливается по duration или после total_queries
+	Duration     string `yaml:"duration"`
+	TotalQueries int    `yaml:"total_queries"`
+
+	Seed int64 `yaml:"seed"`
+
+	// Вес запроса при выборе, по умолчанию 1, 0 - исключить
+	QueryWeights map[string]float64 `yaml:"query_weights"`
+}
+
+// SuiteConfig - набор запросов из нескольких директорий с фильтрами.
+// Фильтры применяются по порядку: queries, include, exclude, tags
+type SuiteConfig struct {
+	// По умолчанию queries_path конфигурации
+	Paths []string `yaml:"paths"`
+
+	// Явный список ID запросов (имя файла без .sql)
+	Queries []string `yaml:"queries"`
+
+	// Glob-шаблоны по ID запроса: query1*, query?
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+
+	// Только запросы с одним из тегов из заголовка "-- tags: a, b"
+	Tags []string `yaml:"tags"`
+}
+
+// QuerySuite - выбранный набор запросов или queries_path, если suite не задан
+func (c *Config) QuerySuite() SuiteConfig {
+	if suite, ok := c.Suites[c.Suite]; ok && c.Suite != "" {
+		return suite
+	}
+
+	return SuiteConfig{Paths: []string{c.QueriesPath}}
+}
+
+type ScenarioConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Duration string `yaml:"duration"`
+	Seed     int64  `yaml:"seed"`
+
+	Classes []QueryClassConfig `yaml:"classes"`
+}
+
+// QueryClassConfig - класс запросов сценария. Класс с weight делит concurrency
+// общих потоков с другими взвешенными классами, класс с threads получает свои потоки
+type QueryClassConfig struct {
+	Name string `yaml:"name"`
+
+	// Источник запросов: queries_path или suite, по умолчанию queries_path конфигурации
+	QueriesPath string `yaml:"queries_path"`
+	Suite       string `yaml:"suite"`
+
+	// Только запросы с одним из тегов из заголовка "-- tags: a, b"
+	Tags []string `yaml:"tags"`
+
+	Weight  float64 `yaml:"weight"`
+	Threads int     `yaml:"threads"`
+
+	// По умолчанию timeout конфигурации
+	Timeout string `yaml:"timeout"`
+}
+
+// Threads - общее число потоков сценария: concurrency для взвешенных классов
+// и выделенные потоки остальных
+func (s *ScenarioConfig) Threads(concurrency int) int {
+	threads := 0
+	weighted := false
+
+	for _, class := range s.Classes {
+		threads += class.Threads
+		if class.Weight > 0 {
+			weighted = true
+		}
+	}
+
+	if weighted {
+		threads += concurrency
+	}
+
+	return threads
+}
+
+type SinkConfig struct {
+	// csv, jsonl, parquet (партиции run_date=/warehouse=/ локально и в s3),
+	// table - таблица результатов на одном из хранилищ
+	Type string `yaml:"type"`
+
+	// Директория файлов, по умолчанию results_path
+	Path string `yaml:"path"`
+
+	// table: хранилище из warehouses и таблица на нем, создается если нет
+	Warehouse string `yaml:"warehouse"`
+	Table     string `yaml:"table"`
+
+	// table: строк в одном INSERT, не чаще flush_interval при неполной пачке
+	BatchSize     int    `yaml:"batch_size"`
+	FlushInterval string `yaml:"flush_interval"`
+
+	// table: повторы неудачного INSERT
+	Retries    int    `yaml:"retries"`
+	RetryDelay string `yaml:"retry_delay"`
+	Timeout    string `yaml:"timeout"`
 }
 
 type S3Config struct {
@@ -42,8 +297,22 @@ type WarehouseConfig struct {
 
 	StorageLocation string `yaml:"storage_location"`
 
+	SchemaTemplate string `yaml:"schema_template,omitempty"`
+
+	// Бюджет времени хранилища с момента подключения к нему
+	MaxDuration string `yaml:"max_duration,omitempty"`
+
+	// Хранилища одного кластера должны быть в одной группе
+	ParallelGroup string `yaml:"parallel_group,omitempty"`
+
+	// Раскрывается в набор хранилищ при загрузке конфига
+	Matrix *MatrixConfig `yaml:"matrix,omitempty"`
+
 	//Параметры подключения
 	Connection ConnectionConfig `yaml:"connection"`
+
+	// индекс исходного блока в warehouses (для номеров строк)
+	source int
 }
 
 type ConnectionConfig struct {
@@ -61,92 +330,16 @@ type ConnectionConfig struct {
 }
 
 func (w *WarehouseConfig) GetSchemaName(baseSchema string) string {
-
-	schema := baseSchema
-
-	if w.StorageLocation == "s3" {
-		schema = schema + "_s3"
-	}
-
-	if w.TableType == "iceberg" {
-		schema = schema + "_iceberg"
+	tmpl := w.SchemaTemplate
+	if tmpl == "" {
+		tmpl = DefaultSchemaTemplate
 	}
-	return schema
-}
 
-func LoadConfig(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	schema, err := renderTemplate(tmpl, w.templateData(baseSchema))
 	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения конфигурационного файла: %w", err)
-
+		log.Printf("WARNING: ошибка шаблона схемы для %s: %v", w.Name, err)
+		return baseSchema
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("ошибка парсинга конфигурации: %w", err)
-	}
-
-	if err := cfg.Validate(); err != nil {
-		return nil, err
-	}
-
-	return &cfg, nil
-}
-
-func (c *Config) Validate() error {
-	if len(c.Warehouses) == 0 {
-		return fmt.Errorf("нет хранилищ данных")
-	}
-
-	if c.Schema == "" {
-		return fmt.Errorf("схема не установлена")
-	}
-
-	if c.QueriesPath == "" {
-		return fmt.Errorf("queries_path не установлен")
-	}
-
-	if c.ResultsPath == "" {
-		return fmt.Errorf("results_path не установлен")
-	}
-
-	if c.S3 != nil && c.S3.Enabled {
-		if c.S3.Endpoint == "" {
-			return fmt.Errorf("s3: endpoint не установлен")
-		}
-
-		if c.S3.AccessKey == "" {
-			return fmt.Errorf("s3: access_key не установлен")
-		}
-
-		if c.S3.SecretKey == "" {
-			return fmt.Errorf("s3: secret_key не установлен")
-		}
-
-		if c.S3.Bucket == "" {
-			return fmt.Errorf("s3: bucket не установлен")
-		}
-
-		if c.S3.UseSSL && c.CertPath == "" {
-			return fmt.Errorf("путь к сертификату не установлен")
-		}
-	}
-
-	if c.Runs < 1 {
-		c.Runs = 1
-	}
-
-	if c.Concurrency < 1 {
-		c.Concurrency = 1
-	}
-
-	if c.ConnectionRetries < 1 {
-		c.ConnectionRetries = 3
-	}
-
-	if c.RetryDelay == "" {
-		c.RetryDelay = "5s"
-	}
-
-	return nil
+	return schema
 }
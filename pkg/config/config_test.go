@@ -1 +1,347 @@
-package config
\ No newline at end of file
+package config
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+const validConfig = `
+schema: tpcds_sf1
+queries_path: ./queries
+results_path: ./results
+timeout: 5m
+connection_timeout: 1m
+warehouses:
+  - name: trino-hive
+    type: trino
+    enabled: true
+    connection:
+      host: trino.local
+      port: 8443
+      username: user
+      database: hive
+`
+
+func TestLoadConfigValid(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config.yaml", validConfig)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Runs != 1 || cfg.Concurrency != 1 || cfg.RetryDelay != "5s" {
+		t.Errorf("defaults not applied: runs=%d concurrency=%d retry_delay=%s",
+			cfg.Runs, cfg.Concurrency, cfg.RetryDelay)
+	}
+}
+
+func TestLoadConfigReportsAllErrors(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config.yaml", `
+schema: tpcds_sf1
+queries_path: ./queries
+results_path: ./results
+timeout: 5 minutes
+connection_timeout: 1m
+unknown_key: 1
+warehouses:
+  - name: trino-hive
+    type: trino
+    table_type: parquet
+    connection:
+      host: trino.local
+      port: 99999
+      username: user
+  - name: trino-hive
+    type: hive
+    connection:
+      username: user
+  - name: other
+    type: clickhouse
+`)
+
+	_, err := LoadConfig(path)
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	expected := map[string]int{
+		"timeout":                           5,
+		"unknown_key":                       7,
+		"warehouses.0.table_type":           11,
+		"warehouses.0.connection.port":      14,
+		"warehouses.0.connection.database":  12,
+		"warehouses.1.name":                 16,
+		"warehouses.1.connection.zk_quorum": 18,
+		"warehouses.2.type":                 21,
+	}
+
+	found := make(map[string]int)
+	for _, e := range errs {
+		key := e.Field
+		if key == "" && strings.Contains(e.Message, "unknown_key") {
+			key = "unknown_key"
+		}
+		found[key] = e.Line
+	}
+
+	for field, line := range expected {
+		got, ok := found[field]
+		if !ok {
+			t.Errorf("missing error for %s in:\n%v", field, err)
+			continue
+		}
+
+		if got != line {
+			t.Errorf("%s: expected line %d, got %d", field, line, got)
+		}
+	}
+}
+
+func TestExampleConfigIsValid(t *testing.T) {
+	if _, err := LoadConfig("../../config/config.yaml.example"); err != nil {
+		t.Fatalf("example config is invalid: %v", err)
+	}
+}
+
+func TestMatrixExpansion(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config.yaml", `
+schema: tpcds_sf1
+queries_path: ./queries
+results_path: ./results
+timeout: 5m
+connection_timeout: 1m
+warehouses:
+  - name: trino
+    type: trino
+    enabled: true
+    matrix:
+      table_type: [hive, iceberg]
+      storage_location: [hdfs, s3]
+      databases:
+        iceberg: iceberg-catalog
+    connection:
+      host: trino.local
+      port: 8443
+      username: user
+      database: hive-catalog
+`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		name, schema, database string
+	}{
+		{"trino-hive-hdfs", "tpcds_sf1", "hive-catalog"},
+		{"trino-hive-s3", "tpcds_sf1_s3", "hive-catalog"},
+		{"trino-iceberg-hdfs", "tpcds_sf1_iceberg", "iceberg-catalog"},
+		{"trino-iceberg-s3", "tpcds_sf1_s3_iceberg", "iceberg-catalog"},
+	}
+
+	if len(cfg.Warehouses) != len(expected) {
+		t.Fatalf("expected %d warehouses, got %d", len(expected), len(cfg.Warehouses))
+	}
+
+	for i, e := range expected {
+		wh := cfg.Warehouses[i]
+		if wh.Name != e.name || wh.GetSchemaName(cfg.Schema) != e.schema || wh.Connection.Database != e.database {
+			t.Errorf("warehouse %d: got (%s, %s, %s), expected (%s, %s, %s)", i,
+				wh.Name, wh.GetSchemaName(cfg.Schema), wh.Connection.Database,
+				e.name, e.schema, e.database)
+		}
+	}
+}
+
+func TestSchemaTemplate(t *testing.T) {
+	wh := WarehouseConfig{
+		Name:            "spark-delta",
+		TableType:       "delta",
+		SchemaTemplate:  "{{.Schema}}_{{.TableType}}_{{.StorageLocation}}",
+		StorageLocation: "s3",
+	}
+
+	if got := wh.GetSchemaName("tpcds"); got != "tpcds_delta_s3" {
+		t.Errorf("unexpected schema %q", got)
+	}
+}
+
+func TestLoadOverlaysIncludesAndProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfig(t, dir, "common.yaml", `
+queries_path: ./queries
+results_path: ./results
+timeout: 5m
+connection_timeout: 1m
+`)
+
+	base := writeConfig(t, dir, "base.yaml", `
+include: common.yaml
+schema: tpcds_sf1
+warehouses:
+  - name: trino-hive
+    type: trino
+    enabled: true
+    connection:
+      host: dev.local
+      port: 8443
+      username: user
+      password: secret
+      database: hive
+profiles:
+  sf100:
+    schema: tpcds_sf100
+    runs: 3
+`)
+
+	overlay := writeConfig(t, dir, "prod.yaml", `
+timeout: 30m
+warehouses:
+  - name: trino-hive
+    connection:
+      host: prod.local
+  - name: vertica
+    type: vertica
+    connection:
+      host: vertica.local
+      port: 5433
+      username: user
+      database: db
+`)
+
+	cfg, err := Load(LoadOptions{Path: base, Overlays: []string{overlay}, Profiles: []string{"sf100"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Schema != "tpcds_sf100" || cfg.Runs != 3 || cfg.Timeout != "30m" || cfg.QueriesPath != "./queries" {
+		t.Errorf("unexpected top level values: %+v", cfg)
+	}
+
+	if len(cfg.Warehouses) != 2 {
+		t.Fatalf("expected 2 warehouses, got %d", len(cfg.Warehouses))
+	}
+
+	trino := cfg.Warehouses[0]
+	if trino.Connection.Host != "prod.local" || trino.Connection.Port != "8443" || !trino.Enabled {
+		t.Errorf("warehouse not deep merged: %+v", trino)
+	}
+
+	if got := cfg.Redacted().Warehouses[0].Connection.Password; got != redactedValue {
+		t.Errorf("password not redacted: %q", got)
+	}
+
+	if cfg.Warehouses[0].Connection.Password != "secret" {
+		t.Errorf("Redacted modified original config")
+	}
+
+	if _, err := Load(LoadOptions{Path: base, Profiles: []string{"missing"}}); err == nil {
+		t.Errorf("expected error for unknown profile")
+	}
+}
+
+func TestValidationErrorsPointToOverlayFile(t *testing.T) {
+	dir := t.TempDir()
+	base := writeConfig(t, dir, "base.yaml", validConfig)
+	overlay := writeConfig(t, dir, "overlay.yaml", `
+warehouses:
+  - name: trino-hive
+    connection:
+      port: 0
+`)
+
+	_, err := Load(LoadOptions{Path: base, Overlays: []string{overlay}})
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("expected one validation error, got %v", err)
+	}
+
+	if errs[0].File != overlay || errs[0].Line != 5 {
+		t.Errorf("unexpected position %s:%d", errs[0].File, errs[0].Line)
+	}
+}
+
+func TestSuiteSelection(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "config.yaml", validConfig+`
+suites:
+  simple:
+    include: ["query1*"]
+  mixed:
+    paths: [./simple, ./hard]
+`)
+
+	broken := writeConfig(t, dir, "broken.yaml", validConfig+`
+suites:
+  mixed:
+    exclude: ["query[2"]
+`)
+
+	_, err := Load(LoadOptions{Path: broken})
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "suites.mixed.exclude.0" {
+		t.Fatalf("expected bad glob error, got %v", err)
+	}
+
+	_, err = Load(LoadOptions{Path: path, Suite: "missing"})
+	if err == nil || !strings.Contains(err.Error(), "-suite") {
+		t.Fatalf("expected unknown suite error pointing to -suite, got %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{Path: path, Suite: "simple"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	suite := cfg.QuerySuite()
+	if len(suite.Paths) != 1 || suite.Paths[0] != "./queries" || suite.Include[0] != "query1*" {
+		t.Errorf("suite defaults not applied: %+v", suite)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"first", RetryPolicy{Backoff: "10s"}, 1, 10 * time.Second},
+		{"doubles", RetryPolicy{Backoff: "10s"}, 3, 40 * time.Second},
+		{"clamped", RetryPolicy{Backoff: "30s", MaxBackoff: "5m"}, 5, 5 * time.Minute},
+		{"large attempt clamped", RetryPolicy{Backoff: "30s", MaxBackoff: "5m"}, 200, 5 * time.Minute},
+		{"large attempt without max", RetryPolicy{Backoff: "1s"}, 200, time.Duration(math.MaxInt64)},
+		{"no backoff", RetryPolicy{}, 50, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
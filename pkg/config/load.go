@@ -0,0 +1,391 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	includeKey  = "include"
+	profilesKey = "profiles"
+)
+
+// LoadOptions - базовый файл, оверлеи поверх него, выбранные профили
+// и набор запросов, заменяющий suite из файлов
+type LoadOptions struct {
+	Path     string
+	Overlays []string
+	Profiles []string
+	Suite    string
+}
+
+// sources - файл, из которого пришел каждый узел объединенного дерева
+type sources map[*yaml.Node]string
+
+func LoadConfig(path string) (*Config, error) {
+	return Load(LoadOptions{Path: path})
+}
+
+// Load читает базовый файл с include, накладывает оверлеи и профили
+// (deep merge: словари сливаются по ключам, списки хранилищ - по name)
+func Load(opts LoadOptions) (*Config, error) {
+	src := make(sources)
+
+	root, err := loadTree(opts.Path, src, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, overlay := range opts.Overlays {
+		tree, err := loadTree(overlay, src, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		root = mergeNodes(root, tree, src)
+	}
+
+	profiles := takeKey(root, profilesKey)
+
+	for _, name := range opts.Profiles {
+		_, profile := childNode(profiles, name)
+		if profile == nil {
+			return nil, fmt.Errorf("профиль %q не найден в конфигурации", name)
+		}
+
+		if profile.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("профиль %q: ожидается словарь", name)
+		}
+
+		root = mergeNodes(root, profile, src)
+	}
+
+	if opts.Suite != "" {
+		suite := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "suite"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: opts.Suite},
+		}}
+		markSources(suite, "-suite", src)
+
+		root = mergeNodes(root, suite, src)
+	}
+
+	return decode(root, src)
+}
+
+func decode(root *yaml.Node, src sources) (*Config, error) {
+	cfg := Config{root: root, sources: src}
+	v := &validator{root: root, sources: src}
+
+	checkKnownFields(v, root, reflect.TypeOf(cfg), nil)
+
+	if err := root.Decode(&cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("ошибка парсинга конфигурации: %w", err)
+		}
+
+		v.addYAMLErrors(typeErr)
+	}
+
+	for i := range cfg.Warehouses {
+		cfg.Warehouses[i].source = i
+	}
+
+	if err := cfg.expandMatrix(); err != nil {
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			return nil, err
+		}
+
+		v.errs = append(v.errs, errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			return nil, err
+		}
+
+		v.errs = append(v.errs, errs...)
+	}
+
+	if err := v.result(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// loadTree читает файл и рекурсивно подставляет include (пути относительно файла)
+func loadTree(path string, src sources, stack []string) (*yaml.Node, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range stack {
+		if p == abs {
+			return nil, fmt.Errorf("циклический include: %s", strings.Join(append(stack, abs), " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения конфигурационного файла: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга конфигурации %s: %w", path, err)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: ожидается словарь на верхнем уровне", path)
+	}
+
+	markSources(root, path, src)
+
+	includes := takeKey(root, includeKey)
+	if includes == nil {
+		return root, nil
+	}
+
+	var files []string
+	switch includes.Kind {
+	case yaml.ScalarNode:
+		files = []string{includes.Value}
+	case yaml.SequenceNode:
+		for _, item := range includes.Content {
+			files = append(files, item.Value)
+		}
+	default:
+		return nil, fmt.Errorf("%s:%d: include должен быть строкой или списком", path, includes.Line)
+	}
+
+	var base *yaml.Node
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+
+		tree, err := loadTree(file, src, append(stack, abs))
+		if err != nil {
+			return nil, err
+		}
+
+		base = mergeNodes(base, tree, src)
+	}
+
+	return mergeNodes(base, root, src), nil
+}
+
+func markSources(node *yaml.Node, file string, src sources) {
+	src[node] = file
+	for _, child := range node.Content {
+		markSources(child, file, src)
+	}
+}
+
+// takeKey удаляет ключ из словаря и возвращает его значение
+func takeKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			node.Content = append(node.Content[:i:i], node.Content[i+2:]...)
+			return value
+		}
+	}
+
+	return nil
+}
+
+// mergeNodes накладывает overlay на base, не изменяя исходные узлы
+func mergeNodes(base, overlay *yaml.Node, src sources) *yaml.Node {
+	if base == nil {
+		return overlay
+	}
+
+	if overlay == nil {
+		return base
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode:
+		merged := copyNode(base, src)
+
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+
+			idx := -1
+			for j := 0; j+1 < len(merged.Content); j += 2 {
+				if merged.Content[j].Value == key.Value {
+					idx = j
+					break
+				}
+			}
+
+			if idx < 0 {
+				merged.Content = append(merged.Content, key, value)
+				continue
+			}
+
+			merged.Content[idx+1] = mergeNodes(merged.Content[idx+1], value, src)
+
+			// значение заменено целиком - позиция ошибок должна указывать на оверлей
+			if merged.Content[idx+1] == value {
+				merged.Content[idx] = key
+			}
+		}
+
+		return merged
+
+	case base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode &&
+		namedItems(base) && namedItems(overlay):
+		merged := copyNode(base, src)
+
+		for _, item := range overlay.Content {
+			_, name := childNode(item, "name")
+
+			idx := -1
+			for j, existing := range merged.Content {
+				if _, n := childNode(existing, "name"); n.Value == name.Value {
+					idx = j
+					break
+				}
+			}
+
+			if idx < 0 {
+				merged.Content = append(merged.Content, item)
+				continue
+			}
+
+			merged.Content[idx] = mergeNodes(merged.Content[idx], item, src)
+		}
+
+		return merged
+
+	default:
+		return overlay
+	}
+}
+
+func copyNode(node *yaml.Node, src sources) *yaml.Node {
+	cp := *node
+	cp.Content = append([]*yaml.Node(nil), node.Content...)
+	src[&cp] = src[node]
+	return &cp
+}
+
+// namedItems - список словарей с полем name (warehouses)
+func namedItems(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+
+		if _, name := childNode(item, "name"); name == nil || name.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkKnownFields - аналог KnownFields(true) для уже объединенного дерева
+func checkKnownFields(v *validator, node *yaml.Node, t reflect.Type, path []string) {
+	if node == nil {
+		return
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		fields := yamlFields(t)
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+
+			field, ok := fields[key]
+			if !ok {
+				v.addf(sub(path, key), "неизвестное поле в %s", t.Name())
+				continue
+			}
+
+			checkKnownFields(v, node.Content[i+1], field, sub(path, key))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+
+		for i, item := range node.Content {
+			checkKnownFields(v, item, t.Elem(), sub(path, fmt.Sprint(i)))
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			checkKnownFields(v, node.Content[i+1], t.Elem(), sub(path, key))
+		}
+	}
+}
+
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			for k, v := range yamlFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		fields[name] = f.Type
+	}
+
+	return fields
+}
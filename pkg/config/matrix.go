@@ -0,0 +1,148 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DefaultSchemaTemplate повторяет прежнее именование: tpcds_sf1, tpcds_sf1_s3, tpcds_sf1_s3_iceberg
+const DefaultSchemaTemplate = `{{.Schema}}{{if eq .StorageLocation "s3"}}_s3{{end}}{{if and .TableType (ne .TableType "hive")}}_{{.TableType}}{{end}}`
+
+type MatrixConfig struct {
+	TableTypes       []string `yaml:"table_type"`
+	StorageLocations []string `yaml:"storage_location"`
+
+	// table_type -> database (каталог trino, база vertica и т.д.)
+	Databases map[string]string `yaml:"databases,omitempty"`
+
+	// По умолчанию имя + значения измерений через дефис: trino-iceberg-s3
+	NameTemplate string `yaml:"name_template,omitempty"`
+}
+
+// TemplateData - поля, доступные в schema_template и name_template
+type TemplateData struct {
+	Name            string
+	Type            string
+	Schema          string
+	TableType       string
+	StorageLocation string
+}
+
+func (w *WarehouseConfig) templateData(baseSchema string) TemplateData {
+	return TemplateData{
+		Name:            w.Name,
+		Type:            w.Type,
+		Schema:          baseSchema,
+		TableType:       w.TableType,
+		StorageLocation: w.StorageLocation,
+	}
+}
+
+func renderTemplate(text string, data TemplateData) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// expandMatrix раскрывает matrix каждого хранилища в конкретные хранилища
+// и проставляет schema_template по умолчанию
+func (c *Config) expandMatrix() error {
+	v := &validator{root: c.root, sources: c.sources}
+
+	var expanded []WarehouseConfig
+
+	for i, wh := range c.Warehouses {
+		if wh.SchemaTemplate == "" {
+			wh.SchemaTemplate = c.SchemaTemplate
+		}
+
+		if wh.Matrix == nil {
+			expanded = append(expanded, wh)
+			continue
+		}
+
+		path := []string{"warehouses", strconv.Itoa(wh.source), "matrix"}
+		if c.root == nil {
+			path[1] = strconv.Itoa(i)
+		}
+
+		matrix := wh.Matrix
+
+		tableTypes := matrix.TableTypes
+		if len(tableTypes) == 0 {
+			tableTypes = []string{wh.TableType}
+		}
+
+		locations := matrix.StorageLocations
+		if len(locations) == 0 {
+			locations = []string{wh.StorageLocation}
+		}
+
+		for _, tableType := range tableTypes {
+			for _, location := range locations {
+				item := wh
+				item.Matrix = nil
+				item.TableType = tableType
+				item.StorageLocation = location
+				item.Connection.Properties = copyMap(wh.Connection.Properties)
+
+				if db, ok := matrix.Databases[tableType]; ok {
+					item.Connection.Database = db
+				}
+
+				name, err := matrix.name(wh.Name, item.templateData(c.Schema))
+				if err != nil {
+					v.addf(sub(path, "name_template"), "ошибка шаблона имени: %v", err)
+					continue
+				}
+
+				item.Name = name
+				expanded = append(expanded, item)
+			}
+		}
+	}
+
+	c.Warehouses = expanded
+
+	return v.result()
+}
+
+func (m *MatrixConfig) name(baseName string, data TemplateData) (string, error) {
+	if m.NameTemplate != "" {
+		return renderTemplate(m.NameTemplate, data)
+	}
+
+	parts := []string{baseName}
+
+	if len(m.TableTypes) > 0 && data.TableType != "" {
+		parts = append(parts, data.TableType)
+	}
+
+	if len(m.StorageLocations) > 0 && data.StorageLocation != "" {
+		parts = append(parts, data.StorageLocation)
+	}
+
+	return strings.Join(parts, "-"), nil
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+
+	return out
+}
@@ -0,0 +1,54 @@
+package config
+
+import "strings"
+
+const redactedValue = "******"
+
+var secretPropertyMarkers = []string{"password", "secret", "token", "credential"}
+
+// Redacted возвращает копию конфигурации со скрытыми паролями и ключами
+func (c *Config) Redacted() *Config {
+	cp := *c
+
+	cp.Warehouses = make([]WarehouseConfig, len(c.Warehouses))
+	for i, wh := range c.Warehouses {
+		wh.Connection.Password = redact(wh.Connection.Password)
+		wh.Connection.Properties = copyMap(wh.Connection.Properties)
+
+		for key, value := range wh.Connection.Properties {
+			if isSecretKey(key) {
+				wh.Connection.Properties[key] = redact(value)
+			}
+		}
+
+		cp.Warehouses[i] = wh
+	}
+
+	if c.S3 != nil {
+		s3 := *c.S3
+		s3.AccessKey = redact(s3.AccessKey)
+		s3.SecretKey = redact(s3.SecretKey)
+		cp.S3 = &s3
+	}
+
+	return &cp
+}
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return redactedValue
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range secretPropertyMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+
+	return false
+}
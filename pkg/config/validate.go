@@ -0,0 +1,744 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	warehouseTypes   = []string{"trino", "impala", "vertica", "hive", "spark"}
+	tableTypes       = []string{"hive", "iceberg", "delta"}
+	storageLocations = []string{"hdfs", "s3"}
+	executionOrders  = []string{OrderWarehouse, OrderInterleaved, OrderRandom}
+	distributions    = []string{ArrivalConstant, ArrivalPoisson}
+	sinkTypes        = []string{"csv", "jsonl", "parquet", "table"}
+
+	// совпадает с executor.ErrorClass
+	errorClasses = []string{"timeout", "cancelled", "syntax", "resource", "admission", "connection", "wrong_result", "unknown"}
+)
+
+// ValidationError - одна проблема конфигурации с позицией в yaml
+type ValidationError struct {
+	File    string
+	Line    int
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	var sb strings.Builder
+
+	switch {
+	case e.File != "" && e.Line > 0:
+		fmt.Fprintf(&sb, "%s:%d: ", e.File, e.Line)
+	case e.Line > 0:
+		fmt.Fprintf(&sb, "строка %d: ", e.Line)
+	case e.File != "":
+		fmt.Fprintf(&sb, "%s: ", e.File)
+	}
+
+	if e.Field != "" {
+		fmt.Fprintf(&sb, "%s: ", e.Field)
+	}
+
+	sb.WriteString(e.Message)
+	return sb.String()
+}
+
+// ValidationErrors - все найденные проблемы конфигурации
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, ve := range e {
+		lines = append(lines, "  "+ve.Error())
+	}
+
+	return fmt.Sprintf("ошибки конфигурации (%d):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+type validator struct {
+	root    *yaml.Node
+	sources sources
+	errs    ValidationErrors
+}
+
+func (v *validator) addf(path []string, format string, args ...any) {
+	keyNode := lookupNode(v.root, path)
+
+	ve := ValidationError{
+		Field:   strings.Join(path, "."),
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	if keyNode != nil {
+		ve.Line = keyNode.Line
+		ve.File = v.sources[keyNode]
+	}
+
+	v.errs = append(v.errs, ve)
+}
+
+func (v *validator) addYAMLErrors(err *yaml.TypeError) {
+	for _, msg := range err.Errors {
+		ve := ValidationError{Message: msg}
+
+		var line int
+		if n, _ := fmt.Sscanf(msg, "line %d:", &line); n == 1 {
+			ve.Line = line
+			ve.Message = strings.TrimSpace(msg[strings.Index(msg, ":")+1:])
+		}
+
+		v.errs = append(v.errs, ve)
+	}
+}
+
+func (v *validator) result() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		if v.errs[i].File != v.errs[j].File {
+			return v.errs[i].File < v.errs[j].File
+		}
+		return v.errs[i].Line < v.errs[j].Line
+	})
+
+	return v.errs
+}
+
+func (c *Config) Validate() error {
+	v := &validator{root: c.root, sources: c.sources}
+
+	if c.Runs < 1 {
+		c.Runs = 1
+	}
+
+	if c.Concurrency < 1 {
+		c.Concurrency = 1
+	}
+
+	if c.ConnectionRetries < 1 {
+		c.ConnectionRetries = 3
+	}
+
+	if len(c.Warehouses) == 0 {
+		v.addf([]string{"warehouses"}, "нет хранилищ данных")
+	}
+
+	if c.Schema == "" {
+		v.addf([]string{"schema"}, "схема не установлена")
+	}
+
+	if c.QueriesPath == "" && c.Suite == "" {
+		v.addf([]string{"queries_path"}, "queries_path не установлен")
+	}
+
+	if len(c.Sinks) == 0 {
+		c.Sinks = []SinkConfig{{Type: "csv"}}
+	}
+
+	for i := range c.Sinks {
+		sink := &c.Sinks[i]
+
+		if !contains(sinkTypes, sink.Type) {
+			v.addf([]string{"sinks", strconv.Itoa(i), "type"}, "неподдерживаемый тип %q, допустимые: %s",
+				sink.Type, strings.Join(sinkTypes, ", "))
+		}
+
+		if sink.Path == "" {
+			sink.Path = c.ResultsPath
+		}
+
+		if sink.Type == "table" {
+			sink.validateTable(v, []string{"sinks", strconv.Itoa(i)}, c.Warehouses)
+		}
+	}
+
+	if c.History != nil && c.History.Enabled && c.History.Path == "" {
+		c.History.Path = filepath.Join(c.ResultsPath, "history.db")
+	}
+
+	for name, suite := range c.Suites {
+		suite.validate(v, sub([]string{"suites"}, name), c.QueriesPath)
+		c.Suites[name] = suite
+	}
+
+	if c.Suite != "" {
+		if _, ok := c.Suites[c.Suite]; !ok {
+			v.addf([]string{"suite"}, "набор запросов %q не найден в suites", c.Suite)
+		}
+	}
+
+	if c.ResultsPath == "" {
+		v.addf([]string{"results_path"}, "results_path не установлен")
+	}
+
+	if c.RetryDelay == "" {
+		c.RetryDelay = "5s"
+	}
+
+	v.duration([]string{"timeout"}, c.Timeout)
+	v.duration([]string{"connection_timeout"}, c.ConnectionTimeout)
+	v.duration([]string{"retry_delay"}, c.RetryDelay)
+
+	if c.MaxTotalDuration != "" {
+		v.duration([]string{"max_total_duration"}, c.MaxTotalDuration)
+	}
+
+	if c.DeadlineGrace != "" {
+		v.nonNegativeDuration([]string{"deadline_grace"}, c.DeadlineGrace)
+	}
+
+	if c.S3 != nil && c.S3.Enabled {
+		c.S3.validate(v, c.CertPath)
+	}
+
+	if c.ExecutionOrder == "" {
+		c.ExecutionOrder = OrderWarehouse
+	}
+
+	if !contains(executionOrders, c.ExecutionOrder) {
+		v.addf([]string{"execution_order"}, "неизвестный порядок %q, допустимые: %s",
+			c.ExecutionOrder, strings.Join(executionOrders, ", "))
+	}
+
+	if c.ExecutionOrder != OrderWarehouse && c.ParallelWarehouses {
+		v.addf([]string{"parallel_warehouses"}, "несовместимо с execution_order: %s", c.ExecutionOrder)
+	}
+
+	for class, policy := range c.RetryPolicies {
+		path := []string{"retry_policies", class}
+
+		if !contains(errorClasses, class) {
+			v.addf(path, "неизвестный класс ошибок, допустимые: %s", strings.Join(errorClasses, ", "))
+		}
+
+		if policy.MaxAttempts < 1 {
+			v.addf(sub(path, "max_attempts"), "должно быть не меньше 1")
+		}
+
+		if policy.Backoff != "" {
+			v.duration(sub(path, "backoff"), policy.Backoff)
+		}
+
+		if policy.MaxBackoff != "" {
+			v.duration(sub(path, "max_backoff"), policy.MaxBackoff)
+		}
+	}
+
+	if cb := c.CircuitBreaker; cb != nil && cb.Enabled {
+		if cb.Threshold == 0 {
+			cb.Threshold = 2
+		}
+
+		if cb.Threshold < 1 {
+			v.addf([]string{"circuit_breaker", "threshold"}, "должно быть не меньше 1")
+		}
+
+		if len(cb.ErrorClasses) == 0 {
+			cb.ErrorClasses = []string{"timeout", "syntax"}
+		}
+
+		for i, class := range cb.ErrorClasses {
+			if !contains(errorClasses, class) {
+				v.addf([]string{"circuit_breaker", "error_classes", strconv.Itoa(i)},
+					"неизвестный класс ошибок %q, допустимые: %s", class, strings.Join(errorClasses, ", "))
+			}
+		}
+	}
+
+	if ol := c.OpenLoop; ol != nil && ol.Enabled {
+		ol.validate(v, c)
+	}
+
+	if sl := c.StepLoad; sl != nil && sl.Enabled {
+		sl.validate(v, c)
+	}
+
+	if sc := c.Scenario; sc != nil && sc.Enabled {
+		sc.validate(v, c)
+	}
+
+	if c.SchemaTemplate != "" {
+		if _, err := renderTemplate(c.SchemaTemplate, TemplateData{}); err != nil {
+			v.addf([]string{"schema_template"}, "ошибка шаблона схемы: %v", err)
+		}
+	}
+
+	names := make(map[string]bool)
+	for i := range c.Warehouses {
+		wh := &c.Warehouses[i]
+
+		idx := i
+		if c.root != nil {
+			idx = wh.source
+		}
+		path := []string{"warehouses", strconv.Itoa(idx)}
+
+		if wh.Name != "" {
+			if names[wh.Name] {
+				v.addf(sub(path, "name"), "имя %q уже используется", wh.Name)
+			}
+			names[wh.Name] = true
+		}
+
+		wh.validate(v, path, c.Schema)
+	}
+
+	return v.result()
+}
+
+func (sl *StepLoadConfig) validate(v *validator, c *Config) {
+	path := []string{"step_load"}
+
+	if len(sl.Steps) == 0 {
+		if sl.MaxConcurrency == 0 {
+			sl.MaxConcurrency = c.Concurrency
+		}
+
+		if sl.MaxConcurrency < 1 {
+			v.addf(sub(path, "max_concurrency"), "должно быть не меньше 1")
+		}
+
+		for n := 1; n < sl.MaxConcurrency; n *= 2 {
+			sl.Steps = append(sl.Steps, n)
+		}
+
+		if sl.MaxConcurrency >= 1 {
+			sl.Steps = append(sl.Steps, sl.MaxConcurrency)
+		}
+	}
+
+	for i, step := range sl.Steps {
+		if step < 1 {
+			v.addf(sub(path, "steps", strconv.Itoa(i)), "должно быть не меньше 1")
+		} else if i > 0 && step <= sl.Steps[i-1] {
+			v.addf(sub(path, "steps", strconv.Itoa(i)), "шаги должны возрастать")
+		}
+	}
+
+	if sl.StepDuration != "" && sl.StepRounds > 0 {
+		v.addf(path, "step_duration и step_rounds взаимоисключающие")
+	}
+
+	if sl.StepDuration == "" && sl.StepRounds == 0 {
+		sl.StepRounds = c.Runs
+	}
+
+	if sl.StepDuration != "" {
+		v.duration(sub(path, "step_duration"), sl.StepDuration)
+	}
+
+	if sl.StepRounds < 0 {
+		v.addf(sub(path, "step_rounds"), "не может быть отрицательным")
+	}
+
+	if sl.MaxP95 != "" {
+		v.duration(sub(path, "max_p95"), sl.MaxP95)
+	}
+
+	if sl.MaxErrorRate < 0 || sl.MaxErrorRate > 1 {
+		v.addf(sub(path, "max_error_rate"), "должно быть от 0 до 1")
+	}
+
+	if c.OpenLoop != nil && c.OpenLoop.Enabled {
+		v.addf(sub(path, "enabled"), "несовместимо с open_loop")
+	}
+
+	if c.ExecutionOrder != OrderWarehouse {
+		v.addf(sub(path, "enabled"), "несовместимо с execution_order: %s", c.ExecutionOrder)
+	}
+}
+
+func (s *SinkConfig) validateTable(v *validator, path []string, warehouses []WarehouseConfig) {
+	if s.Table == "" {
+		v.addf(sub(path, "table"), "table не установлен")
+	}
+
+	found := false
+	for _, wh := range warehouses {
+		if wh.Name == s.Warehouse {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		v.addf(sub(path, "warehouse"), "хранилище %q не найдено в warehouses", s.Warehouse)
+	}
+
+	if s.BatchSize == 0 {
+		s.BatchSize = 100
+	}
+
+	if s.BatchSize < 1 {
+		v.addf(sub(path, "batch_size"), "должно быть не меньше 1")
+	}
+
+	if s.FlushInterval == "" {
+		s.FlushInterval = "30s"
+	}
+
+	if s.RetryDelay == "" {
+		s.RetryDelay = "5s"
+	}
+
+	if s.Timeout == "" {
+		s.Timeout = "1m"
+	}
+
+	v.nonNegativeDuration(sub(path, "flush_interval"), s.FlushInterval)
+	v.nonNegativeDuration(sub(path, "retry_delay"), s.RetryDelay)
+	v.duration(sub(path, "timeout"), s.Timeout)
+
+	if s.Retries < 0 {
+		v.addf(sub(path, "retries"), "не может быть отрицательным")
+	}
+}
+
+func (s *SuiteConfig) validate(v *validator, path []string, queriesPath string) {
+	if len(s.Paths) == 0 && queriesPath != "" {
+		s.Paths = []string{queriesPath}
+	}
+
+	if len(s.Paths) == 0 {
+		v.addf(sub(path, "paths"), "нужна хотя бы одна директория с запросами")
+	}
+
+	for key, patterns := range map[string][]string{"include": s.Include, "exclude": s.Exclude} {
+		for i, pattern := range patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				v.addf(sub(path, key, strconv.Itoa(i)), "неверный шаблон %q: %v", pattern, err)
+			}
+		}
+	}
+}
+
+func (sc *ScenarioConfig) validate(v *validator, c *Config) {
+	path := []string{"scenario"}
+
+	v.duration(sub(path, "duration"), sc.Duration)
+
+	if len(sc.Classes) == 0 {
+		v.addf(sub(path, "classes"), "нужен хотя бы один класс запросов")
+	}
+
+	names := make(map[string]bool)
+	for i := range sc.Classes {
+		class := &sc.Classes[i]
+		classPath := sub(path, "classes", strconv.Itoa(i))
+
+		if class.Name == "" {
+			v.addf(sub(classPath, "name"), "name не установлен")
+		} else if names[class.Name] {
+			v.addf(sub(classPath, "name"), "имя %q уже используется", class.Name)
+		}
+		names[class.Name] = true
+
+		if class.Suite != "" {
+			if class.QueriesPath != "" {
+				v.addf(classPath, "queries_path и suite взаимоисключающие")
+			}
+
+			if _, ok := c.Suites[class.Suite]; !ok {
+				v.addf(sub(classPath, "suite"), "набор запросов %q не найден в suites", class.Suite)
+			}
+		} else if class.QueriesPath == "" {
+			class.QueriesPath = c.QueriesPath
+		}
+
+		if class.Timeout != "" {
+			v.duration(sub(classPath, "timeout"), class.Timeout)
+		}
+
+		switch {
+		case class.Weight < 0:
+			v.addf(sub(classPath, "weight"), "вес не может быть отрицательным")
+		case class.Threads < 0:
+			v.addf(sub(classPath, "threads"), "не может быть отрицательным")
+		case class.Weight > 0 && class.Threads > 0:
+			v.addf(classPath, "weight и threads взаимоисключающие")
+		case class.Weight == 0 && class.Threads == 0:
+			v.addf(classPath, "нужно задать weight или threads")
+		}
+	}
+
+	if c.OpenLoop != nil && c.OpenLoop.Enabled {
+		v.addf(sub(path, "enabled"), "несовместимо с open_loop")
+	}
+
+	if c.StepLoad != nil && c.StepLoad.Enabled {
+		v.addf(sub(path, "enabled"), "несовместимо с step_load")
+	}
+
+	if c.ExecutionOrder != OrderWarehouse {
+		v.addf(sub(path, "enabled"), "несовместимо с execution_order: %s", c.ExecutionOrder)
+	}
+}
+
+func (ol *OpenLoopConfig) validate(v *validator, c *Config) {
+	path := []string{"open_loop"}
+
+	if ol.ArrivalRate <= 0 {
+		v.addf(sub(path, "arrival_rate"), "должно быть больше 0 (запросов в минуту)")
+	}
+
+	if ol.Distribution == "" {
+		ol.Distribution = ArrivalConstant
+	}
+
+	if !contains(distributions, ol.Distribution) {
+		v.addf(sub(path, "distribution"), "неизвестное распределение %q, допустимые: %s",
+			ol.Distribution, strings.Join(distributions, ", "))
+	}
+
+	if ol.MaxOutstanding == 0 {
+		ol.MaxOutstanding = c.Concurrency
+	}
+
+	if ol.MaxOutstanding < 1 {
+		v.addf(sub(path, "max_outstanding"), "должно быть не меньше 1")
+	}
+
+	if ol.Duration == "" && ol.TotalQueries <= 0 {
+		v.addf(path, "нужно задать duration или total_queries")
+	}
+
+	if ol.Duration != "" {
+		v.duration(sub(path, "duration"), ol.Duration)
+	}
+
+	for id, weight := range ol.QueryWeights {
+		if weight < 0 {
+			v.addf(sub(path, "query_weights", id), "вес не может быть отрицательным")
+		}
+	}
+
+	if c.ExecutionOrder != OrderWarehouse {
+		v.addf(sub(path, "enabled"), "несовместимо с execution_order: %s", c.ExecutionOrder)
+	}
+}
+
+func (s *S3Config) validate(v *validator, certPath string) {
+	path := []string{"s3_config"}
+
+	if s.Endpoint == "" {
+		v.addf(sub(path, "endpoint"), "endpoint не установлен")
+	}
+
+	if s.AccessKey == "" {
+		v.addf(sub(path, "access_key"), "access_key не установлен")
+	}
+
+	if s.SecretKey == "" {
+		v.addf(sub(path, "secret_key"), "secret_key не установлен")
+	}
+
+	if s.Bucket == "" {
+		v.addf(sub(path, "bucket"), "bucket не установлен")
+	}
+
+	if s.UseSSL && certPath == "" {
+		v.addf(sub(path, "use_ssl"), "путь к сертификату (cert_path) не установлен")
+	}
+}
+
+func (w *WarehouseConfig) validate(v *validator, path []string, baseSchema string) {
+	if w.Name == "" {
+		v.addf(sub(path, "name"), "имя хранилища не установлено")
+	}
+
+	if w.SchemaTemplate != "" {
+		if _, err := renderTemplate(w.SchemaTemplate, w.templateData(baseSchema)); err != nil {
+			v.addf(sub(path, "schema_template"), "ошибка шаблона схемы: %v", err)
+		}
+	}
+
+	if w.TableType != "" && !contains(tableTypes, w.TableType) {
+		v.addf(sub(path, "table_type"), "неизвестный table_type %q, допустимые: %s",
+			w.TableType, strings.Join(tableTypes, ", "))
+	}
+
+	if w.StorageLocation != "" && !contains(storageLocations, w.StorageLocation) {
+		v.addf(sub(path, "storage_location"), "неизвестный storage_location %q, допустимые: %s",
+			w.StorageLocation, strings.Join(storageLocations, ", "))
+	}
+
+	if w.MaxDuration != "" {
+		v.duration(sub(path, "max_duration"), w.MaxDuration)
+	}
+
+	conn := &w.Connection
+	connPath := sub(path, "connection")
+
+	required := func(field, value string) {
+		if value == "" {
+			v.addf(sub(connPath, field), "обязательное поле для типа %s", w.Type)
+		}
+	}
+
+	switch w.Type {
+	case "trino":
+		required("host", conn.Host)
+		required("port", conn.Port)
+		required("username", conn.Username)
+		required("database", conn.Database)
+
+	case "impala":
+		required("host", conn.Host)
+		required("port", conn.Port)
+
+	case "vertica":
+		required("host", conn.Host)
+		required("port", conn.Port)
+		required("username", conn.Username)
+		required("database", conn.Database)
+
+	case "hive", "spark":
+		required("zk_quorum", conn.ZKQuorum)
+
+		for _, member := range splitList(conn.ZKQuorum) {
+			_, port, err := net.SplitHostPort(member)
+			if err != nil {
+				v.addf(sub(connPath, "zk_quorum"), "неверный адрес %q: ожидается host:port", member)
+				continue
+			}
+
+			v.port(sub(connPath, "zk_quorum"), port)
+		}
+
+	case "":
+		v.addf(sub(path, "type"), "тип хранилища не установлен")
+
+	default:
+		v.addf(sub(path, "type"), "неизвестный тип хранилища %q, допустимые: %s",
+			w.Type, strings.Join(warehouseTypes, ", "))
+	}
+
+	if conn.Port != "" {
+		v.port(sub(connPath, "port"), conn.Port)
+	}
+}
+
+func (v *validator) duration(path []string, value string) {
+	if value == "" {
+		v.addf(path, "длительность не установлена")
+		return
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.addf(path, "неверная длительность %q: %v", value, err)
+		return
+	}
+
+	if d <= 0 {
+		v.addf(path, "длительность должна быть положительной: %s", value)
+	}
+}
+
+func (v *validator) nonNegativeDuration(path []string, value string) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.addf(path, "неверная длительность %q: %v", value, err)
+		return
+	}
+
+	if d < 0 {
+		v.addf(path, "длительность не может быть отрицательной: %s", value)
+	}
+}
+
+func (v *validator) port(path []string, value string) {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		v.addf(path, "неверный порт %q", value)
+		return
+	}
+
+	if port < 1 || port > 65535 {
+		v.addf(path, "порт %d вне диапазона 1-65535", port)
+	}
+}
+
+// lookupNode возвращает узел ключа самого глубокого существующего элемента пути
+func lookupNode(root *yaml.Node, path []string) *yaml.Node {
+	if root == nil {
+		return nil
+	}
+
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	found := node
+
+	for _, key := range path {
+		keyNode, next := childNode(node, key)
+		if next == nil {
+			break
+		}
+
+		node = next
+		found = keyNode
+	}
+
+	return found
+}
+
+// childNode возвращает узел ключа (для строки) и узел значения
+func childNode(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i], node.Content[i+1]
+			}
+		}
+
+	case yaml.SequenceNode:
+		idx, err := strconv.Atoi(key)
+		if err == nil && idx >= 0 && idx < len(node.Content) {
+			return node.Content[idx], node.Content[idx]
+		}
+	}
+
+	return nil, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sub(path []string, keys ...string) []string {
+	out := make([]string, 0, len(path)+len(keys))
+	out = append(out, path...)
+	return append(out, keys...)
+}
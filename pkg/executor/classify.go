@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type ErrorClass string
+
+const (
+	ErrorClassNone        ErrorClass = ""
+	ErrorClassTimeout     ErrorClass = "timeout"
+	ErrorClassCancelled   ErrorClass = "cancelled"
+	ErrorClassSyntax      ErrorClass = "syntax"
+	ErrorClassResource    ErrorClass = "resource"
+	ErrorClassAdmission   ErrorClass = "admission"
+	ErrorClassConnection  ErrorClass = "connection"
+	ErrorClassWrongResult ErrorClass = "wrong_result"
+	ErrorClassUnknown     ErrorClass = "unknown"
+)
+
+type classMarkers struct {
+	class   ErrorClass
+	markers []string
+}
+
+// Проверяются до сетевых ошибок: сообщения об очереди и старте движка
+// часто содержат "timeout" или "connection refused"
+var priorityClassMarkers = []classMarkers{
+	{ErrorClassCancelled, []string{
+		"user_canceled", "query was canceled", "query was cancelled", "cancelled by user", "canceled by user",
+	}},
+	{ErrorClassAdmission, []string{
+		"no_nodes_available", "no nodes available", "query_queue_full", "too many queued queries",
+		"admission for query exceeded timeout", "rejected query from pool", "queue full",
+		"kyuubi.session.engine", "to launched", "engine is starting", "failed to get engine",
+		"server_starting_up",
+	}},
+}
+
+var errorClassMarkers = []classMarkers{
+	{ErrorClassResource, []string{
+		"exceeded_local_memory_limit", "exceeded_global_memory_limit", "exceeded_spill_limit",
+		"memory limit exceeded", "outofmemory", "out of memory", "insufficient resources",
+		"insufficient memory", "gc overhead limit", "insufficient_resources", "inner memory limit",
+		"cluster_out_of_memory", "disk space",
+	}},
+	{ErrorClassTimeout, []string{
+		"exceeded_time_limit", "timeout", "timed out", "query_timeout",
+	}},
+	{ErrorClassSyntax, []string{
+		"syntax_error", "syntax error", "mismatched input", "parseexception", "semanticexception",
+		"analysisexception", "analysis exception", "cannot be resolved", "does not exist",
+		"table_not_found", "column_not_found", "schema_not_found", "function_not_found",
+		"not_supported", "type_mismatch", "could not resolve", "invalid reference",
+	}},
+}
+
+// ClassifyError относит ошибку выполнения к категории для политики повторов и отчетов
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCancelled
+	}
+
+	msg := strings.ToLower(err.Error())
+
+	if class := matchMarkers(msg, priorityClassMarkers); class != ErrorClassNone {
+		return class
+	}
+
+	if IsConnectionError(err) {
+		return ErrorClassConnection
+	}
+
+	if class := matchMarkers(msg, errorClassMarkers); class != ErrorClassNone {
+		return class
+	}
+
+	return ErrorClassUnknown
+}
+
+func matchMarkers(msg string, groups []classMarkers) ErrorClass {
+	for _, group := range groups {
+		for _, marker := range group.markers {
+			if strings.Contains(msg, marker) {
+				return group.class
+			}
+		}
+	}
+
+	return ErrorClassNone
+}
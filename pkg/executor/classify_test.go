@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ErrorClassNone},
+		{"context deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{"context canceled", fmt.Errorf("query: %w", context.Canceled), ErrorClassCancelled},
+
+		// trino
+		{"trino timeout", errors.New("EXCEEDED_TIME_LIMIT: Query exceeded maximum run time limit of 5.00m"), ErrorClassTimeout},
+		{"trino memory", errors.New("EXCEEDED_LOCAL_MEMORY_LIMIT: Query exceeded per-node memory limit of 4GB"), ErrorClassResource},
+		{"trino syntax", errors.New("SYNTAX_ERROR: line 1:8: mismatched input 'form'"), ErrorClassSyntax},
+		{"trino missing table", errors.New("TABLE_NOT_FOUND: line 3:6: Table 'hive.tpcds.store_sales' does not exist"), ErrorClassSyntax},
+		{"trino no nodes", errors.New("NO_NODES_AVAILABLE: No nodes available to run query"), ErrorClassAdmission},
+		{"trino canceled", errors.New("USER_CANCELED: Query was canceled"), ErrorClassCancelled},
+		{"trino refused", errors.New(`Post "http://trino:8080/v1/statement": dial tcp 10.0.0.1:8080: connect: connection refused`), ErrorClassConnection},
+
+		// hive
+		{"hive parse", errors.New("Error while compiling statement: FAILED: ParseException line 1:7 cannot recognize input near 'selec'"), ErrorClassSyntax},
+		{"hive semantic", errors.New("FAILED: SemanticException [Error 10001]: Line 1:14 Table not found 'foo'"), ErrorClassSyntax},
+		{"hive oom", errors.New("java.lang.OutOfMemoryError: GC overhead limit exceeded"), ErrorClassResource},
+		{"hive timeout", errors.New("Query timed out after 300 seconds"), ErrorClassTimeout},
+		{"hive session", errors.New("Invalid SessionHandle: SessionHandle [4f1c]"), ErrorClassConnection},
+
+		// spark через kyuubi: старт движка - очередь, хотя в сообщении есть timeout
+		{"kyuubi engine start", errors.New("KyuubiSQLException: Timeout(180000 ms, you can modify kyuubi.session.engine.initialize.timeout to change it) to launched SPARK_SQL engine"), ErrorClassAdmission},
+		{"kyuubi engine refused", errors.New("Failed to get engine: connection refused"), ErrorClassAdmission},
+		{"spark analysis", errors.New("AnalysisException: [UNRESOLVED_COLUMN] A column with name `x` cannot be resolved"), ErrorClassSyntax},
+
+		// impala
+		{"impala memory", errors.New("Memory limit exceeded: Failed to allocate row batch"), ErrorClassResource},
+		{"impala queue", errors.New("Rejected query from pool root.default: queue full, limit=200"), ErrorClassAdmission},
+		{"impala admission timeout", errors.New("Admission for query exceeded timeout 60000ms in pool root.default"), ErrorClassAdmission},
+		{"impala inactivity", errors.New("Query 4e43:2f19 expired due to client inactivity (timeout is 5m0s)"), ErrorClassTimeout},
+		{"impala analysis", errors.New("AnalysisException: Could not resolve table reference: 'tpcds.foo'"), ErrorClassSyntax},
+
+		// vertica
+		{"vertica syntax", errors.New(`Error: [42601] Syntax error at or near "form"`), ErrorClassSyntax},
+		{"vertica resources", errors.New("Error: [53000] Insufficient resources to execute plan on pool general"), ErrorClassResource},
+		{"vertica bad conn", errors.New("driver: bad connection"), ErrorClassConnection},
+
+		// сетевые ошибки без сообщений движка
+		{"dial error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}, ErrorClassConnection},
+		{"eof", fmt.Errorf("read response: %w", io.EOF), ErrorClassConnection},
+
+		{"unknown", errors.New("Division by zero"), ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
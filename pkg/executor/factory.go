@@ -6,10 +6,21 @@ import (
 	"tpcds_benchmark/pkg/connection"
 )
 
+// CreateExecutor создает экзекьютор, который переподключается при обрыве сессии
 func CreateExecutor(
 	wh config.WarehouseConfig,
 	connMgr *connection.ConnectionManager,
 	baseSchema string,
+) (QueryExecutor, error) {
+	return NewReconnectingExecutor(wh.Name, func() (QueryExecutor, error) {
+		return connectExecutor(wh, connMgr, baseSchema)
+	})
+}
+
+func connectExecutor(
+	wh config.WarehouseConfig,
+	connMgr *connection.ConnectionManager,
+	baseSchema string,
 ) (QueryExecutor, error) {
 	schema := wh.GetSchemaName(baseSchema)
 
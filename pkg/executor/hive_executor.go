@@ -39,6 +39,7 @@ func (e *HiveExecutor) Execute(ctx context.Context, query string, schema string)
 			Duration: 0,
 			Success:  false,
 			Error:    fmt.Sprintf("ошибка при выборе схемы: %v", cursor.Err),
+			Err:      cursor.Err,
 		}, nil
 	}
 
@@ -54,6 +55,7 @@ func (e *HiveExecutor) Execute(ctx context.Context, query string, schema string)
 			Duration:       duration,
 			Success:        false,
 			Error:          cursor.Err.Error(),
+			Err:            cursor.Err,
 		}, nil
 	}
 
@@ -65,6 +67,8 @@ func (e *HiveExecutor) Execute(ctx context.Context, query string, schema string)
 		Duration:       duration,
 		Success:        true,
 		RowCount:       rowCount,
+
+		RowCountUnknown: true,
 	}, nil
 
 }
@@ -18,4 +18,25 @@ type QueryResult struct {
 	RowCount       int
 	Success        bool
 	Error          string
+
+	// исходная ошибка драйвера, если запрос не выполнен
+	Err error
+
+	// экзекьютор не считает строки (hive/spark), RowCount не сравнивается с ожидаемым
+	RowCountUnknown bool
+
+	// соединение пересоздано после обрыва во время запроса; сам запрос не повторялся
+	Reconnected bool
+
+	// результаты отдельных инструкций многочастного запроса, пусто для одной инструкции
+	Parts []PartResult
+
+	// статистика движка (trino: cpu, байты, память), nil если движок ее не отдает
+	Metrics map[string]any
+}
+
+type PartResult struct {
+	Duration time.Duration
+	RowCount int
+	Metrics  map[string]any
 }
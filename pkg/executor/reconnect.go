@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// признаки разорванного соединения в текстах ошибок драйверов
+var connectionErrorMarkers = []string{
+	"bad connection",
+	"broken pipe",
+	"connection reset",
+	"connection refused",
+	"use of closed network connection",
+	"unexpected eof",
+	"invalid session",
+	"invalid sessionhandle",
+	"session is closed",
+	"session has expired",
+}
+
+type ConnectFunc func() (QueryExecutor, error)
+
+// ReconnectingExecutor пересоздает соединение через ConnectionManager
+// (с его ретраями и backoff), если сессия оборвалась посреди прогона.
+// Запрос не повторяется: возвращается исходная ошибка с Reconnected,
+// а повтор решает политика повторов класса connection
+type ReconnectingExecutor struct {
+	name    string
+	connect ConnectFunc
+
+	mu   sync.Mutex
+	exec QueryExecutor
+}
+
+func NewReconnectingExecutor(name string, connect ConnectFunc) (*ReconnectingExecutor, error) {
+	exec, err := connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconnectingExecutor{
+		name:    name,
+		connect: connect,
+		exec:    exec,
+	}, nil
+}
+
+func (e *ReconnectingExecutor) Name() string {
+	return e.name
+}
+
+func (e *ReconnectingExecutor) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.exec == nil {
+		return nil
+	}
+
+	return e.exec.Close()
+}
+
+func (e *ReconnectingExecutor) Execute(ctx context.Context, query string, schema string) (*QueryResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result, err := e.execute(ctx, query, schema)
+	if !IsConnectionError(resultError(result, err)) || ctx.Err() != nil {
+		return result, err
+	}
+
+	log.Printf("[%s] соединение потеряно (%v), переподключение...", e.name, resultError(result, err))
+
+	if e.exec != nil {
+		e.exec.Close()
+		e.exec = nil
+	}
+
+	exec, connErr := e.connect()
+	if connErr != nil {
+		log.Printf("[%s] ERROR: переподключение не удалось: %v", e.name, connErr)
+		return result, err
+	}
+
+	e.exec = exec
+
+	log.Printf("[%s] переподключение успешно", e.name)
+
+	if result == nil {
+		result = &QueryResult{Success: false, Error: err.Error(), Err: err}
+		err = nil
+	}
+	result.Reconnected = true
+
+	return result, err
+}
+
+func (e *ReconnectingExecutor) execute(ctx context.Context, query string, schema string) (*QueryResult, error) {
+	if e.exec == nil {
+		// предыдущее переподключение не удалось - сразу пробуем снова
+		return nil, driver.ErrBadConn
+	}
+
+	return e.exec.Execute(ctx, query, schema)
+}
+
+func resultError(result *QueryResult, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if result != nil && !result.Success {
+		if result.Err != nil {
+			return result.Err
+		}
+
+		return errors.New(result.Error)
+	}
+
+	return nil
+}
+
+// IsConnectionError распознает обрыв соединения: driver.ErrBadConn,
+// транспортные ошибки thrift, EOF и сетевые ошибки
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var transportErr thrift.TTransportException
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	// ошибка установки соединения - сервер запрос не получал; ошибки чтения и записи
+	// (в том числе таймауты) могли случиться, когда запрос уже выполнялся, и считаются
+	// обрывом только по признакам выше и ниже
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	if msg == "eof" || strings.HasSuffix(msg, ": eof") {
+		return true
+	}
+
+	for _, marker := range connectionErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// fakeExecutor возвращает заданную ошибку и считает вызовы
+type fakeExecutor struct {
+	err    error
+	calls  int
+	closed bool
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, query string, schema string) (*QueryResult, error) {
+	f.calls++
+
+	if f.err != nil {
+		return &QueryResult{Success: false, Error: f.err.Error(), Err: f.err}, nil
+	}
+
+	return &QueryResult{Success: true, RowCount: 1}, nil
+}
+
+func (f *fakeExecutor) Name() string { return "fake" }
+
+func (f *fakeExecutor) Close() error {
+	f.closed = true
+	return nil
+}
+
+// connector выдает экзекьюторы по очереди; nil - ошибка подключения
+func connector(execs ...*fakeExecutor) (ConnectFunc, *int) {
+	connects := 0
+
+	return func() (QueryExecutor, error) {
+		exec := execs[connects]
+		connects++
+
+		if exec == nil {
+			return nil, errors.New("connection refused")
+		}
+
+		return exec, nil
+	}, &connects
+}
+
+func TestReconnectDoesNotRerunQuery(t *testing.T) {
+	broken := &fakeExecutor{err: driver.ErrBadConn}
+	fresh := &fakeExecutor{}
+
+	connect, connects := connector(broken, fresh)
+
+	e, err := NewReconnectingExecutor("wh", connect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := e.Execute(context.Background(), "select 1", "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Success || !result.Reconnected || !errors.Is(result.Err, driver.ErrBadConn) {
+		t.Errorf("expected original failure marked reconnected, got %+v", result)
+	}
+
+	if *connects != 2 || !broken.closed || fresh.calls != 0 {
+		t.Errorf("connects=%d closed=%v fresh.calls=%d", *connects, broken.closed, fresh.calls)
+	}
+
+	// повтор (решение политики) идет на новом соединении
+	result, err = e.Execute(context.Background(), "select 1", "s")
+	if err != nil || !result.Success || result.Reconnected || fresh.calls != 1 {
+		t.Errorf("retry on new connection: result=%+v err=%v calls=%d", result, err, fresh.calls)
+	}
+}
+
+func TestReconnectIgnoresQueryErrors(t *testing.T) {
+	exec := &fakeExecutor{err: errors.New("line 1:8: mismatched input")}
+	connect, connects := connector(exec)
+
+	e, err := NewReconnectingExecutor("wh", connect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ := e.Execute(context.Background(), "select", "s")
+	if result.Reconnected || *connects != 1 || exec.closed {
+		t.Errorf("reconnected on query error: %+v connects=%d", result, *connects)
+	}
+}
+
+func TestReconnectFailureRetriesConnectNextTime(t *testing.T) {
+	broken := &fakeExecutor{err: driver.ErrBadConn}
+	fresh := &fakeExecutor{}
+
+	connect, connects := connector(broken, nil, fresh)
+
+	e, err := NewReconnectingExecutor("wh", connect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ := e.Execute(context.Background(), "select 1", "s")
+	if result.Reconnected || result.Success {
+		t.Errorf("failed reconnect reported as reconnected: %+v", result)
+	}
+
+	// соединения нет: запрос не выполняется, сразу новое подключение
+	result, err = e.Execute(context.Background(), "select 1", "s")
+	if err != nil || result.Success || !result.Reconnected || !errors.Is(result.Err, driver.ErrBadConn) {
+		t.Errorf("expected bad conn with reconnect, got %+v, %v", result, err)
+	}
+
+	if *connects != 3 || fresh.calls != 0 {
+		t.Errorf("connects=%d fresh.calls=%d", *connects, fresh.calls)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad conn", driver.ErrBadConn, true},
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}, true},
+		{"read reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"read timeout", &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, false},
+		{"write other", &net.OpError{Op: "write", Net: "tcp", Err: errors.New("message too long")}, false},
+		{"deadline", context.DeadlineExceeded, false},
+		{"marker", errors.New("Invalid SessionHandle: abc"), true},
+		{"syntax", errors.New("mismatched input 'form'"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConnectionError(tt.err); got != tt.want {
+				t.Errorf("IsConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
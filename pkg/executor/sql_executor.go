@@ -32,18 +32,32 @@ func (e *SQLExecutor) Close() error {
 
 func (e *SQLExecutor) Execute(ctx context.Context, query string, schema string) (*QueryResult, error) {
 
+	var (
+		args  []any
+		stats *trinoStats
+	)
+
+	if e.warehouseType == "trino" {
+		stats = newTrinoStats()
+		args = stats.args()
+	}
+
 	start := time.Now()
-	rows, err := e.conn.QueryContext(ctx, query)
+	rows, err := e.conn.QueryContext(ctx, query, args...)
 	end := time.Now()
 	duration := end.Sub(start)
 
 	if err != nil {
+		stats.wait(ctx)
+
 		return &QueryResult{
 			StartTimestamp: start,
 			EndTimestamp:   end,
 			Duration:       duration,
 			Success:        false,
 			Error:          err.Error(),
+			Err:            err,
+			Metrics:        stats.metrics(),
 		}, nil
 	}
 
@@ -56,21 +70,30 @@ func (e *SQLExecutor) Execute(ctx context.Context, query string, schema string)
 	}
 
 	if err := rows.Err(); err != nil {
+		rows.Close()
+		stats.wait(ctx)
+
 		return &QueryResult{
 			StartTimestamp: start,
 			EndTimestamp:   end,
 			Duration:       duration,
 			Success:        false,
 			Error:          err.Error(),
+			Err:            err,
+			Metrics:        stats.metrics(),
 		}, nil
 	}
 
+	rows.Close()
+	stats.wait(ctx)
+
 	return &QueryResult{
 		StartTimestamp: start,
 		EndTimestamp:   end,
 		Duration:       duration,
 		Success:        true,
 		RowCount:       rowCount,
+		Metrics:        stats.metrics(),
 	}, nil
 
 }
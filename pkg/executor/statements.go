@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecuteStatements выполняет инструкции по порядку на одном соединении и суммирует
+// длительность и строки; выполнение останавливается на первой неудачной инструкции
+func ExecuteStatements(ctx context.Context, exec QueryExecutor, statements []string, schema string) (*QueryResult, error) {
+	if len(statements) == 1 {
+		return exec.Execute(ctx, statements[0], schema)
+	}
+
+	total := &QueryResult{Success: true}
+
+	for i, stmt := range statements {
+		part, err := exec.Execute(ctx, stmt, schema)
+		if part == nil {
+			return total, fmt.Errorf("часть %d/%d: %w", i+1, len(statements), err)
+		}
+
+		if i == 0 {
+			total.StartTimestamp = part.StartTimestamp
+		}
+
+		total.EndTimestamp = part.EndTimestamp
+		total.Duration += part.Duration
+		total.Reconnected = total.Reconnected || part.Reconnected
+		total.RowCountUnknown = total.RowCountUnknown || part.RowCountUnknown
+
+		if err != nil {
+			return total, fmt.Errorf("часть %d/%d: %w", i+1, len(statements), err)
+		}
+
+		total.Parts = append(total.Parts, PartResult{
+			Duration: part.Duration,
+			RowCount: part.RowCount,
+			Metrics:  part.Metrics,
+		})
+
+		if !part.Success {
+			total.Success = false
+			total.Error = fmt.Sprintf("часть %d/%d: %s", i+1, len(statements), part.Error)
+			total.Err = part.Err
+			return total, nil
+		}
+
+		total.RowCount += part.RowCount
+	}
+
+	return total, nil
+}
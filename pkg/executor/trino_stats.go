@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// trinoStatsWait - сколько ждать статистику завершенного запроса после чтения результата
+const trinoStatsWait = 500 * time.Millisecond
+
+// trinoStats получает статистику запроса от драйвера trino (progress callback)
+// и хранит последнюю. Драйвер шлет ее из отдельной горутины без блокировки:
+// обновление с итоговым состоянием может потеряться или прийти после чтения
+// результата, поэтому wait ждет его ограниченное время, а metrics отмечает
+// в stats_final, получена ли итоговая статистика
+type trinoStats struct {
+	mu    sync.Mutex
+	info  *trino.QueryProgressInfo
+	final chan struct{}
+}
+
+func newTrinoStats() *trinoStats {
+	return &trinoStats{final: make(chan struct{})}
+}
+
+func (s *trinoStats) Update(info trino.QueryProgressInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isFinal() {
+		return
+	}
+
+	s.info = &info
+
+	if finalState(info.QueryStats.State) {
+		close(s.final)
+	}
+}
+
+func (s *trinoStats) isFinal() bool {
+	select {
+	case <-s.final:
+		return true
+	default:
+		return false
+	}
+}
+
+func finalState(state string) bool {
+	return state == "FINISHED" || state == "FAILED"
+}
+
+// wait ждет итоговую статистику не дольше trinoStatsWait
+func (s *trinoStats) wait(ctx context.Context) {
+	if s == nil {
+		return
+	}
+
+	timer := time.NewTimer(trinoStatsWait)
+	defer timer.Stop()
+
+	select {
+	case <-s.final:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+func (s *trinoStats) args() []any {
+	return []any{
+		sql.Named("X-Trino-Progress-Callback", s),
+		sql.Named("X-Trino-Progress-Callback-Period", time.Second),
+	}
+}
+
+// metrics - последняя полученная статистика, nil для других движков (s == nil);
+// stats_final = false - итоговая не пришла и значения промежуточные
+func (s *trinoStats) metrics() map[string]any {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.info == nil {
+		return nil
+	}
+
+	st := s.info.QueryStats
+
+	return map[string]any{
+		"query_id":             s.info.QueryId,
+		"state":                st.State,
+		"nodes":                st.Nodes,
+		"total_splits":         st.TotalSplits,
+		"cpu_time_ms":          st.CPUTimeMillis,
+		"wall_time_ms":         st.WallTimeMillis,
+		"queued_time_ms":       st.QueuedTimeMillis,
+		"elapsed_time_ms":      st.ElapsedTimeMillis,
+		"processed_rows":       st.ProcessedRows,
+		"processed_bytes":      st.ProcessedBytes,
+		"physical_input_bytes": st.PhysicalInputBytes,
+		"peak_memory_bytes":    st.PeakMemoryBytes,
+		"spilled_bytes":        st.SpilledBytes,
+		"stats_final":          s.isFinal(),
+	}
+}
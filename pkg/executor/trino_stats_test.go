@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+func progress(state string, cpuMs int64) trino.QueryProgressInfo {
+	info := trino.QueryProgressInfo{QueryId: "q"}
+	info.QueryStats.State = state
+	info.QueryStats.CPUTimeMillis = cpuMs
+
+	return info
+}
+
+func TestTrinoStatsFinal(t *testing.T) {
+	s := newTrinoStats()
+	s.Update(progress("RUNNING", 10))
+
+	// итоговое обновление приходит из горутины драйвера после чтения результата
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.Update(progress("FINISHED", 30))
+	}()
+
+	start := time.Now()
+	s.wait(context.Background())
+	if time.Since(start) >= trinoStatsWait {
+		t.Error("wait did not return on final update")
+	}
+
+	m := s.metrics()
+	if m["stats_final"] != true || m["cpu_time_ms"] != int64(30) {
+		t.Errorf("metrics: %v", m)
+	}
+
+	// запоздавшее промежуточное обновление не затирает итог
+	s.Update(progress("RUNNING", 5))
+	if m := s.metrics(); m["state"] != "FINISHED" || m["cpu_time_ms"] != int64(30) {
+		t.Errorf("final stats overwritten: %v", m)
+	}
+}
+
+func TestTrinoStatsPartial(t *testing.T) {
+	s := newTrinoStats()
+	s.Update(progress("RUNNING", 10))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.wait(ctx)
+
+	m := s.metrics()
+	if m["stats_final"] != false || m["state"] != "RUNNING" {
+		t.Errorf("metrics: %v", m)
+	}
+}
+
+func TestTrinoStatsNil(t *testing.T) {
+	var s *trinoStats
+	s.wait(context.Background())
+
+	if m := s.metrics(); m != nil {
+		t.Errorf("nil stats returned %v", m)
+	}
+
+	if m := newTrinoStats().metrics(); m != nil {
+		t.Errorf("stats without updates returned %v", m)
+	}
+}
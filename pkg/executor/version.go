@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// VersionReporter - экзекьютор умеет запросить версию движка
+type VersionReporter interface {
+	EngineVersion(ctx context.Context) (string, error)
+}
+
+// EngineVersion запрашивает версию движка, если экзекьютор это поддерживает
+func EngineVersion(ctx context.Context, exec QueryExecutor) (string, error) {
+	vr, ok := exec.(VersionReporter)
+	if !ok {
+		return "", fmt.Errorf("экзекьютор %s не сообщает версию движка", exec.Name())
+	}
+
+	return vr.EngineVersion(ctx)
+}
+
+func (e *SQLExecutor) EngineVersion(ctx context.Context) (string, error) {
+	var version string
+
+	if e.warehouseType == "trino" {
+		// version() в trino есть не во всех версиях, node_version координатора - всегда
+		err := e.conn.QueryRowContext(ctx, "SELECT node_version FROM system.runtime.nodes WHERE coordinator").Scan(&version)
+		if err == nil {
+			return version, nil
+		}
+	}
+
+	err := e.conn.QueryRowContext(ctx, "SELECT version()").Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("пустой ответ на запрос версии")
+	}
+
+	return version, err
+}
+
+func (e *HiveExecutor) EngineVersion(ctx context.Context) (string, error) {
+	cursor := e.conn.Cursor()
+	defer cursor.Close()
+
+	cursor.Exec(ctx, "SELECT version()")
+	if cursor.Err != nil {
+		return "", cursor.Err
+	}
+
+	if !cursor.HasMore(ctx) {
+		if cursor.Err != nil {
+			return "", cursor.Err
+		}
+		return "", fmt.Errorf("пустой ответ на запрос версии")
+	}
+
+	var version string
+	cursor.FetchOne(ctx, &version)
+
+	return version, cursor.Err
+}
+
+func (e *ReconnectingExecutor) EngineVersion(ctx context.Context) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.exec == nil {
+		return "", fmt.Errorf("нет соединения с %s", e.name)
+	}
+
+	return EngineVersion(ctx, e.exec)
+}
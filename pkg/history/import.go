@@ -0,0 +1,76 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// ImportCSV загружает файл результатов CSVStorage как отдельный запуск;
+// время запуска берется по первому и последнему результату. Запуск и результаты
+// пишутся одной транзакцией, чтобы неудачный импорт можно было повторить
+func (s *Store) ImportCSV(path string) (Run, error) {
+	results, err := storage.ReadCSVResults(path)
+	if err != nil {
+		return Run{}, err
+	}
+
+	// строки, замененные повтором после -resume, в историю не попадают
+	results = storage.LatestResults(results)
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	if _, err := s.FindRun(name); err == nil {
+		return Run{}, fmt.Errorf("запуск %s уже есть в истории", name)
+	}
+
+	run := Run{
+		Name:   name,
+		Source: "import",
+		Status: RunImported,
+	}
+
+	for _, r := range results {
+		start := r.StartTimestamp
+		if start.IsZero() {
+			start = r.SaveResultTimestamp
+		}
+
+		if !start.IsZero() && (run.StartedAt.IsZero() || start.Before(run.StartedAt)) {
+			run.StartedAt = start
+		}
+
+		if r.SaveResultTimestamp.After(run.FinishedAt) {
+			run.FinishedAt = r.SaveResultTimestamp
+		}
+
+		if run.Suite == "" {
+			run.Suite = r.Suite
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Run{}, err
+	}
+
+	run.ID, err = insertRun(tx, run)
+	if err != nil {
+		tx.Rollback()
+		return Run{}, fmt.Errorf("ошибка записи запуска в историю: %w", err)
+	}
+
+	if err := saveResults(tx, run.ID, results); err != nil {
+		tx.Rollback()
+		return Run{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Run{}, err
+	}
+
+	run.Results = len(results)
+
+	return run, nil
+}
@@ -0,0 +1,112 @@
+package history
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// Sink - получатель результатов, который записывает запуск в историю
+type Sink struct {
+	path string
+	run  Run
+
+	mu      sync.Mutex
+	store   *Store
+	runID   int64
+	pending []storage.BenchmarkResult
+	status  string
+}
+
+func NewSink(path string, run Run) *Sink {
+	run.Source = "run"
+	run.Status = RunRunning
+
+	return &Sink{
+		path:   path,
+		run:    run,
+		status: RunCompleted,
+	}
+}
+
+func (s *Sink) Open() error {
+	store, err := Open(s.path)
+	if err != nil {
+		return err
+	}
+
+	runID, err := store.BeginRun(s.run)
+	if err != nil {
+		store.Close()
+		return fmt.Errorf("ошибка записи запуска в историю: %w", err)
+	}
+
+	s.store = store
+	s.runID = runID
+
+	log.Printf("запуск %d записывается в историю %s", runID, s.path)
+
+	return nil
+}
+
+func (s *Sink) Save(result storage.BenchmarkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, result)
+	return nil
+}
+
+// Flush пишет накопленные результаты одной транзакцией
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.flush()
+}
+
+func (s *Sink) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	if err := s.store.SaveResults(s.runID, s.pending); err != nil {
+		return err
+	}
+
+	s.pending = nil
+	return nil
+}
+
+// Interrupt - запуск прерван сигналом, Close отметит его как interrupted
+func (s *Sink) Interrupt() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = RunInterrupted
+}
+
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store == nil {
+		return nil
+	}
+
+	err := s.flush()
+	if finishErr := s.store.FinishRun(s.runID, time.Now(), s.status); finishErr != nil && err == nil {
+		err = finishErr
+	}
+
+	s.store.Close()
+	s.store = nil
+
+	return err
+}
+
+func (s *Sink) Artifacts() []storage.Artifact {
+	return nil
+}
@@ -0,0 +1,459 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+	"tpcds_benchmark/pkg/storage"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+const (
+	RunRunning     = "running"
+	RunCompleted   = "completed"
+	RunInterrupted = "interrupted"
+	RunImported    = "imported"
+)
+
+// Store - локальная история запусков в sqlite: строка на запуск и его результаты
+type Store struct {
+	db *sql.DB
+}
+
+// Run - запуск бенчмарка в истории
+type Run struct {
+	ID int64
+
+	// имя файла результатов без расширения
+	Name string
+
+	// run - записан бенчмарком, import - загружен из csv
+	Source      string
+	Status      string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Suite       string
+	GitRevision string
+
+	// снимок конфигурации без паролей (yaml)
+	Config string
+
+	// число результатов, заполняется только в Runs
+	Results int
+}
+
+// миграции схемы, номер версии хранится в PRAGMA user_version
+var migrations = []string{
+	`CREATE TABLE runs (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		name         TEXT NOT NULL UNIQUE,
+		source       TEXT NOT NULL,
+		status       TEXT NOT NULL,
+		started_at   TEXT,
+		finished_at  TEXT,
+		suite        TEXT,
+		git_revision TEXT,
+		config       TEXT
+	);
+
+	CREATE TABLE results (
+		id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id                INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+		save_result_timestamp TEXT,
+		start_timestamp       TEXT,
+		end_timestamp         TEXT,
+		scheduled_timestamp   TEXT,
+		query_id              TEXT NOT NULL,
+		warehouse             TEXT NOT NULL,
+		schema_name           TEXT,
+		run_number            INTEGER,
+		thread_id             INTEGER,
+		concurrency           INTEGER,
+		attempt               INTEGER,
+		reconnected           INTEGER,
+		status                TEXT NOT NULL,
+		error_class           TEXT,
+		error_message         TEXT,
+		duration_ms           INTEGER,
+		queue_delay_ms        INTEGER,
+		row_count             INTEGER,
+		query_class           TEXT,
+		suite                 TEXT,
+		part_durations_ms     TEXT,
+		part_row_counts       TEXT,
+		engine_metrics        TEXT
+	);
+
+	CREATE INDEX results_run ON results(run_id, warehouse, query_id);`,
+}
+
+// Open открывает базу истории, создавая файл и схему при необходимости
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия истории %s: %w", path, err)
+	}
+
+	// sqlite пишет из одного соединения
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка обновления схемы истории %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// BeginRun добавляет запуск; запуск с тем же именем (--resume) продолжается:
+// меняется только статус и сбрасывается finished_at, а started_at, config, suite
+// и git_revision остаются от первой попытки, результаты дописываются к прежним
+func (s *Store) BeginRun(run Run) (int64, error) {
+	var id int64
+
+	err := s.db.QueryRow("SELECT id FROM runs WHERE name = ?", run.Name).Scan(&id)
+	switch {
+	case err == nil:
+		_, err = s.db.Exec("UPDATE runs SET status = ?, finished_at = NULL WHERE id = ?", run.Status, id)
+		return id, err
+
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, err
+	}
+
+	return insertRun(s.db, run)
+}
+
+// execer - *sql.DB или *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func insertRun(db execer, run Run) (int64, error) {
+	res, err := db.Exec(
+		`INSERT INTO runs (name, source, status, started_at, finished_at, suite, git_revision, config)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Name,
+		run.Source,
+		run.Status,
+		formatTime(run.StartedAt),
+		formatTime(run.FinishedAt),
+		run.Suite,
+		run.GitRevision,
+		run.Config,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (s *Store) FinishRun(id int64, finishedAt time.Time, status string) error {
+	_, err := s.db.Exec("UPDATE runs SET finished_at = ?, status = ? WHERE id = ?", formatTime(finishedAt), status, id)
+	return err
+}
+
+// SaveResults записывает результаты запуска одной транзакцией
+func (s *Store) SaveResults(runID int64, results []storage.BenchmarkResult) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := saveResults(tx, runID, results); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func saveResults(tx *sql.Tx, runID int64, results []storage.BenchmarkResult) error {
+	stmt, err := tx.Prepare(
+		`INSERT INTO results (
+			run_id, save_result_timestamp, start_timestamp, end_timestamp, scheduled_timestamp,
+			query_id, warehouse, schema_name, run_number, thread_id, concurrency, attempt, reconnected,
+			status, error_class, error_message, duration_ms, queue_delay_ms, row_count,
+			query_class, suite, part_durations_ms, part_row_counts, engine_metrics
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range results {
+		_, err := stmt.Exec(
+			runID,
+			formatTime(r.SaveResultTimestamp),
+			formatTime(r.StartTimestamp),
+			formatTime(r.EndTimestamp),
+			formatTime(r.ScheduledTimestamp),
+			r.QueryID,
+			r.Warehouse,
+			r.Schema,
+			r.RunNumber,
+			r.ThreadID,
+			r.Concurrency,
+			r.Attempt,
+			r.Reconnected,
+			r.Status,
+			r.ErrorClass,
+			r.ErrorMsg,
+			r.DurationMs,
+			r.QueueDelayMs,
+			r.RowCount,
+			r.QueryClass,
+			r.Suite,
+			jsonValue(r.PartDurationsMs),
+			jsonValue(r.PartRowCounts),
+			jsonValue(r.EngineMetrics),
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка записи результата %s в историю: %w", r.QueryID, err)
+		}
+	}
+
+	return nil
+}
+
+// Runs - все запуски истории, новые первыми
+func (s *Store) Runs() ([]Run, error) {
+	rows, err := s.db.Query(
+		`SELECT r.id, r.name, r.source, r.status, r.started_at, r.finished_at, r.suite, r.git_revision,
+			(SELECT COUNT(*) FROM results WHERE run_id = r.id)
+		FROM runs r
+		ORDER BY r.started_at DESC, r.id DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+
+	for rows.Next() {
+		var (
+			run                Run
+			started, finished  sql.NullString
+			suite, gitRevision sql.NullString
+		)
+
+		if err := rows.Scan(&run.ID, &run.Name, &run.Source, &run.Status, &started, &finished, &suite, &gitRevision, &run.Results); err != nil {
+			return nil, err
+		}
+
+		row := &dbRow{}
+		run.StartedAt = row.time("started_at", started)
+		run.FinishedAt = row.time("finished_at", finished)
+		run.Suite = suite.String
+		run.GitRevision = gitRevision.String
+
+		if row.err != nil {
+			return nil, fmt.Errorf("запуск %s: %w", run.Name, row.err)
+		}
+
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// FindRun ищет запуск по id или имени
+func (s *Store) FindRun(ref string) (Run, error) {
+	query := "SELECT id, name, source, status, started_at, finished_at, suite, git_revision, config FROM runs WHERE name = ?"
+
+	var arg any = ref
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		query = "SELECT id, name, source, status, started_at, finished_at, suite, git_revision, config FROM runs WHERE id = ?"
+		arg = id
+	}
+
+	var (
+		run                        Run
+		started, finished          sql.NullString
+		suite, gitRevision, config sql.NullString
+	)
+
+	err := s.db.QueryRow(query, arg).Scan(&run.ID, &run.Name, &run.Source, &run.Status, &started, &finished, &suite, &gitRevision, &config)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Run{}, fmt.Errorf("запуск %s не найден в истории", ref)
+	}
+	if err != nil {
+		return Run{}, err
+	}
+
+	row := &dbRow{}
+	run.StartedAt = row.time("started_at", started)
+	run.FinishedAt = row.time("finished_at", finished)
+	run.Suite = suite.String
+	run.GitRevision = gitRevision.String
+	run.Config = config.String
+
+	if row.err != nil {
+		return Run{}, fmt.Errorf("запуск %s: %w", run.Name, row.err)
+	}
+
+	return run, nil
+}
+
+// Results - результаты запуска в порядке записи
+func (s *Store) Results(runID int64) ([]storage.BenchmarkResult, error) {
+	rows, err := s.db.Query(
+		`SELECT id, save_result_timestamp, start_timestamp, end_timestamp, scheduled_timestamp,
+			query_id, warehouse, schema_name, run_number, thread_id, concurrency, attempt, reconnected,
+			status, error_class, error_message, duration_ms, queue_delay_ms, row_count,
+			query_class, suite, part_durations_ms, part_row_counts, engine_metrics
+		FROM results WHERE run_id = ? ORDER BY id`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []storage.BenchmarkResult
+
+	for rows.Next() {
+		var (
+			id                              int64
+			r                               storage.BenchmarkResult
+			saved, start, end, scheduled    sql.NullString
+			schema, errorClass, errorMsg    sql.NullString
+			queryClass, suite               sql.NullString
+			partDurations, partRows, engine sql.NullString
+		)
+
+		err := rows.Scan(
+			&id, &saved, &start, &end, &scheduled,
+			&r.QueryID, &r.Warehouse, &schema, &r.RunNumber, &r.ThreadID, &r.Concurrency, &r.Attempt, &r.Reconnected,
+			&r.Status, &errorClass, &errorMsg, &r.DurationMs, &r.QueueDelayMs, &r.RowCount,
+			&queryClass, &suite, &partDurations, &partRows, &engine,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		row := &dbRow{}
+		r.SaveResultTimestamp = row.time("save_result_timestamp", saved)
+		r.StartTimestamp = row.time("start_timestamp", start)
+		r.EndTimestamp = row.time("end_timestamp", end)
+		r.ScheduledTimestamp = row.time("scheduled_timestamp", scheduled)
+		r.Schema = schema.String
+		r.ErrorClass = errorClass.String
+		r.ErrorMsg = errorMsg.String
+		r.QueryClass = queryClass.String
+		r.Suite = suite.String
+
+		row.json("part_durations_ms", partDurations, &r.PartDurationsMs)
+		row.json("part_row_counts", partRows, &r.PartRowCounts)
+		row.json("engine_metrics", engine, &r.EngineMetrics)
+
+		if row.err != nil {
+			return nil, fmt.Errorf("запуск %d: результат %d: %w", runID, id, row.err)
+		}
+
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+func formatTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
+
+// dbRow разбирает значения строки истории; NULL и пустое значение - нулевые,
+// первая ошибка разбора сохраняется в err
+type dbRow struct {
+	err error
+}
+
+func (r *dbRow) fail(name string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("колонка %s: %w", name, err)
+	}
+}
+
+func (r *dbRow) time(name string, value sql.NullString) time.Time {
+	if !value.Valid || value.String == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value.String)
+	if err != nil {
+		r.fail(name, err)
+	}
+
+	return t
+}
+
+func jsonValue[T any](value T) any {
+	data, err := json.Marshal(value)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+
+	return string(data)
+}
+
+func (r *dbRow) json(name string, value sql.NullString, target any) {
+	if !value.Valid || value.String == "" {
+		return
+	}
+
+	if err := json.Unmarshal([]byte(value.String), target); err != nil {
+		r.fail(name, err)
+	}
+}
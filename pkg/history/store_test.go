@@ -0,0 +1,293 @@
+package history
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"tpcds_benchmark/pkg/storage"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func TestOpenMigratesSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.db")
+
+	s := openStore(t, path)
+
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+
+	if version != len(migrations) {
+		t.Fatalf("user_version = %d, want %d", version, len(migrations))
+	}
+
+	if _, err := s.BeginRun(Run{Name: "run", Source: "run", Status: RunRunning}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// повторное открытие не применяет миграции заново и сохраняет данные
+	reopened := openStore(t, path)
+
+	runs, err := reopened.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(runs) != 1 || runs[0].Name != "run" {
+		t.Errorf("runs after reopen: %+v", runs)
+	}
+}
+
+func TestBeginRunResumeKeepsFirstAttempt(t *testing.T) {
+	s := openStore(t, filepath.Join(t.TempDir(), "history.db"))
+
+	first := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	id, err := s.BeginRun(Run{Name: "run", Source: "run", Status: RunRunning, StartedAt: first, Config: "first: 1\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.FinishRun(id, first.Add(time.Hour), RunInterrupted); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := s.BeginRun(Run{Name: "run", Source: "run", Status: RunRunning, StartedAt: first.Add(2 * time.Hour), Config: "second: 2\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resumed != id {
+		t.Fatalf("resume created a new run: %d != %d", resumed, id)
+	}
+
+	run, err := s.FindRun("run")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if run.Status != RunRunning || !run.FinishedAt.IsZero() {
+		t.Errorf("status not reset on resume: %+v", run)
+	}
+
+	if !run.StartedAt.Equal(first) || run.Config != "first: 1\n" {
+		t.Errorf("resume overwrote first attempt: started %v, config %q", run.StartedAt, run.Config)
+	}
+}
+
+func TestImportCSVRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	written := []storage.BenchmarkResult{
+		{
+			SaveResultTimestamp: start.Add(2 * time.Second),
+			StartTimestamp:      start,
+			EndTimestamp:        start.Add(1500 * time.Millisecond),
+			QueryID:             "q14",
+			Warehouse:           "trino",
+			Schema:              "tpcds",
+			RunNumber:           1,
+			ThreadID:            2,
+			DurationMs:          1500,
+			Status:              storage.StatusSuccess,
+			RowCount:            100,
+			Attempt:             1,
+			Concurrency:         4,
+			Suite:               "tpcds",
+			PartDurationsMs:     []int{1000, 500},
+			PartRowCounts:       []int{60, 40},
+		},
+		{
+			SaveResultTimestamp: start.Add(3 * time.Second),
+			QueryID:             "q2",
+			Warehouse:           "trino",
+			RunNumber:           1,
+			Status:              storage.StatusError,
+			ErrorMsg:            "line 1: it's broken",
+			ErrorClass:          "syntax",
+			Reconnected:         true,
+			Suite:               "tpcds",
+		},
+	}
+
+	csv := storage.NewCSVStorage(dir, "run-1.csv")
+	if err := csv.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range written {
+		if err := csv.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := csv.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openStore(t, filepath.Join(dir, "history.db"))
+
+	run, err := s.ImportCSV(csv.GetFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if run.Name != "run-1" || run.Status != RunImported || run.Results != 2 || run.Suite != "tpcds" {
+		t.Errorf("imported run: %+v", run)
+	}
+
+	if !run.StartedAt.Equal(start) || !run.FinishedAt.Equal(start.Add(3*time.Second)) {
+		t.Errorf("run bounds: %v - %v", run.StartedAt, run.FinishedAt)
+	}
+
+	read, err := s.Results(run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(read) != len(written) {
+		t.Fatalf("expected %d results, got %d", len(written), len(read))
+	}
+
+	for i, want := range written {
+		got := read[i]
+
+		if !got.StartTimestamp.Equal(want.StartTimestamp) || !got.EndTimestamp.Equal(want.EndTimestamp) ||
+			!got.SaveResultTimestamp.Equal(want.SaveResultTimestamp) {
+			t.Errorf("%s: timestamps changed: %+v", want.QueryID, got)
+		}
+
+		if got.QueryID != want.QueryID || got.Warehouse != want.Warehouse || got.Schema != want.Schema ||
+			got.RunNumber != want.RunNumber || got.ThreadID != want.ThreadID || got.DurationMs != want.DurationMs ||
+			got.Status != want.Status || got.ErrorMsg != want.ErrorMsg || got.ErrorClass != want.ErrorClass ||
+			got.RowCount != want.RowCount || got.Attempt != want.Attempt || got.Reconnected != want.Reconnected ||
+			got.Concurrency != want.Concurrency || got.Suite != want.Suite {
+			t.Errorf("%s: fields changed\n got: %+v\nwant: %+v", want.QueryID, got, want)
+		}
+
+		if !slices.Equal(got.PartDurationsMs, want.PartDurationsMs) || !slices.Equal(got.PartRowCounts, want.PartRowCounts) {
+			t.Errorf("%s: parts changed: %v %v", want.QueryID, got.PartDurationsMs, got.PartRowCounts)
+		}
+	}
+
+	if _, err := s.ImportCSV(csv.GetFilePath()); err == nil {
+		t.Error("second import of the same file should fail")
+	}
+}
+
+func TestResultsReportsMalformedValues(t *testing.T) {
+	s := openStore(t, filepath.Join(t.TempDir(), "history.db"))
+
+	id, err := s.BeginRun(Run{Name: "run", Source: "run", Status: RunRunning})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := storage.BenchmarkResult{QueryID: "q1", Warehouse: "trino", Status: storage.StatusSuccess, StartTimestamp: time.Now()}
+	if err := s.SaveResults(id, []storage.BenchmarkResult{ok}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		column string
+		value  string
+	}{
+		{"start_timestamp", "yesterday"},
+		{"engine_metrics", "{broken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			if _, err := s.db.Exec("UPDATE results SET "+tt.column+" = ? WHERE run_id = ?", tt.value, id); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := s.Results(id)
+			if err == nil || !strings.Contains(err.Error(), tt.column) {
+				t.Errorf("expected %s parse error, got %v", tt.column, err)
+			}
+
+			if _, err := s.db.Exec("UPDATE results SET "+tt.column+" = NULL WHERE run_id = ?", id); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if _, err := s.db.Exec("UPDATE runs SET started_at = 'soon' WHERE id = ?", id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.FindRun("run"); err == nil || !strings.Contains(err.Error(), "started_at") {
+		t.Errorf("expected started_at parse error from FindRun, got %v", err)
+	}
+
+	if _, err := s.Runs(); err == nil || !strings.Contains(err.Error(), "started_at") {
+		t.Errorf("expected started_at parse error from Runs, got %v", err)
+	}
+}
+
+func TestImportCSVRollsBackOnFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	csv := storage.NewCSVStorage(dir, "run-2.csv")
+	if err := csv.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := csv.Save(storage.BenchmarkResult{SaveResultTimestamp: time.Now(), QueryID: "q1", Warehouse: "trino", Status: storage.StatusSuccess}); err != nil {
+		t.Fatal(err)
+	}
+	if err := csv.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openStore(t, filepath.Join(dir, "history.db"))
+
+	// запись результатов падает после того, как строка запуска уже вставлена
+	if _, err := s.db.Exec(`CREATE TRIGGER fail_results BEFORE INSERT ON results BEGIN SELECT RAISE(ABORT, 'disk full'); END`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ImportCSV(csv.GetFilePath()); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected import to fail, got %v", err)
+	}
+
+	runs, err := s.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("failed import left runs behind: %+v", runs)
+	}
+
+	if _, err := s.db.Exec("DROP TRIGGER fail_results"); err != nil {
+		t.Fatal(err)
+	}
+
+	// повторный импорт того же файла не упирается в проверку дубликата
+	run, err := s.ImportCSV(csv.GetFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if run.Results != 1 {
+		t.Errorf("expected 1 result after retry, got %d", run.Results)
+	}
+}
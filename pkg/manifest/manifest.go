@@ -0,0 +1,301 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/query"
+	"tpcds_benchmark/pkg/utils"
+
+	"github.com/elastic/go-sysinfo"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StatusRunning     = "running"
+	StatusCompleted   = "completed"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+)
+
+// Manifest - описание запуска: окружение, конфигурация, запросы и версии движков,
+// по которому результаты можно воспроизвести и сопоставить
+type Manifest struct {
+	mu sync.Mutex
+
+	RunID      string     `json:"run_id"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// -resume: начало текущей попытки и прерванные попытки по порядку,
+	// started_at остается от первой
+	ResumedAt        *time.Time `json:"resumed_at,omitempty"`
+	PreviousAttempts []Attempt  `json:"previous_attempts,omitempty"`
+
+	Tool Tool `json:"tool"`
+	Host Host `json:"host"`
+
+	// sha256 конфигурации со скрытыми паролями в yaml
+	ConfigHash string         `json:"config_hash"`
+	Config     map[string]any `json:"config"`
+
+	// sha256 от id и хешей всех запросов по порядку
+	QuerySetHash string  `json:"query_set_hash"`
+	Queries      []Query `json:"queries"`
+
+	Warehouses []Warehouse `json:"warehouses"`
+}
+
+// Attempt - прерванная попытка запуска, продолженная через -resume
+type Attempt struct {
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	GitRevision  string     `json:"git_revision,omitempty"`
+	ConfigHash   string     `json:"config_hash"`
+	QuerySetHash string     `json:"query_set_hash"`
+}
+
+type Tool struct {
+	Version     string `json:"version,omitempty"`
+	GitRevision string `json:"git_revision,omitempty"`
+	GoVersion   string `json:"go_version"`
+}
+
+// Host - машина, с которой запущен бенчмарк
+type Host struct {
+	Hostname      string `json:"hostname,omitempty"`
+	OS            string `json:"os"`
+	OSVersion     string `json:"os_version,omitempty"`
+	Architecture  string `json:"architecture"`
+	KernelVersion string `json:"kernel_version,omitempty"`
+	CPUs          int    `json:"cpus"`
+	MemoryBytes   uint64 `json:"memory_bytes,omitempty"`
+	Timezone      string `json:"timezone,omitempty"`
+	Containerized *bool  `json:"containerized,omitempty"`
+
+	// ошибка чтения сведений о системе, заполнены только поля из runtime
+	Error string `json:"error,omitempty"`
+}
+
+type Query struct {
+	ID         string   `json:"id"`
+	Path       string   `json:"path,omitempty"`
+	SHA256     string   `json:"sha256"`
+	Statements int      `json:"statements"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// Warehouse - хранилище и версия движка, пусто если соединение не открывалось
+type Warehouse struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Schema        string `json:"schema"`
+	Enabled       bool   `json:"enabled"`
+	EngineVersion string `json:"engine_version,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// New собирает манифест запуска runID со статусом running
+func New(runID string, cfg *config.Config, queries []query.Query) (*Manifest, error) {
+	m := &Manifest{
+		RunID:     runID,
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+		Tool: Tool{
+			Version:     utils.ToolVersion(),
+			GitRevision: utils.GitRevision(),
+			GoVersion:   runtime.Version(),
+		},
+		Host: hostInfo(),
+	}
+
+	snapshot, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации конфигурации: %w", err)
+	}
+
+	if err := yaml.Unmarshal(snapshot, &m.Config); err != nil {
+		return nil, fmt.Errorf("ошибка сериализации конфигурации: %w", err)
+	}
+
+	m.ConfigHash = hash(snapshot)
+
+	set := sha256.New()
+	for _, q := range queries {
+		mq := Query{
+			ID:         q.ID,
+			Path:       q.Path,
+			SHA256:     queryHash(q),
+			Statements: max(len(q.Statements), 1),
+			Tags:       q.Tags,
+		}
+
+		fmt.Fprintf(set, "%s %s\n", mq.ID, mq.SHA256)
+		m.Queries = append(m.Queries, mq)
+	}
+	m.QuerySetHash = hex.EncodeToString(set.Sum(nil))
+
+	for _, wh := range cfg.Warehouses {
+		m.Warehouses = append(m.Warehouses, Warehouse{
+			Name:    wh.Name,
+			Type:    wh.Type,
+			Schema:  wh.GetSchemaName(cfg.Schema),
+			Enabled: wh.Enabled,
+		})
+	}
+
+	return m, nil
+}
+
+// queryHash - хеш файла запроса как он лежит на диске, иначе текста запроса
+func queryHash(q query.Query) string {
+	if q.Path != "" {
+		if data, err := os.ReadFile(q.Path); err == nil {
+			return hash(data)
+		}
+	}
+
+	return hash([]byte(q.SQL))
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hostInfo() Host {
+	h := Host{
+		OS:           runtime.GOOS,
+		Architecture: runtime.GOARCH,
+		CPUs:         runtime.NumCPU(),
+	}
+
+	host, err := sysinfo.Host()
+	if err != nil {
+		h.Error = err.Error()
+		return h
+	}
+
+	info := host.Info()
+
+	h.Hostname = info.Hostname
+	h.KernelVersion = info.KernelVersion
+	h.Timezone = info.Timezone
+	h.Containerized = info.Containerized
+
+	if info.Architecture != "" {
+		h.Architecture = info.Architecture
+	}
+
+	if info.OS != nil {
+		h.OS = info.OS.Name
+		h.OSVersion = info.OS.Version
+	}
+
+	if mem, err := host.Memory(); err == nil {
+		h.MemoryBytes = mem.Total
+	}
+
+	return h
+}
+
+// Resume связывает манифест с прерванным запуском, манифест которого лежит в path:
+// started_at берется от первой попытки, прежние попытки сохраняются в previous_attempts.
+// Отсутствие файла не ошибка - манифест мог не успеть записаться
+func (m *Manifest) Resume(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка чтения манифеста прерванного запуска: %w", err)
+	}
+
+	var prev Manifest
+	if err := json.Unmarshal(data, &prev); err != nil {
+		return fmt.Errorf("ошибка разбора манифеста прерванного запуска %s: %w", path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt := Attempt{
+		StartedAt:    prev.StartedAt,
+		FinishedAt:   prev.FinishedAt,
+		Status:       prev.Status,
+		Error:        prev.Error,
+		GitRevision:  prev.Tool.GitRevision,
+		ConfigHash:   prev.ConfigHash,
+		QuerySetHash: prev.QuerySetHash,
+	}
+	if prev.ResumedAt != nil {
+		attempt.StartedAt = *prev.ResumedAt
+	}
+
+	resumed := m.StartedAt
+	m.ResumedAt = &resumed
+	m.StartedAt = prev.StartedAt
+	m.PreviousAttempts = append(prev.PreviousAttempts, attempt)
+
+	return nil
+}
+
+// SetEngine записывает версию движка хранилища или ошибку ее получения
+func (m *Manifest) SetEngine(warehouse, version string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.Warehouses {
+		if m.Warehouses[i].Name != warehouse {
+			continue
+		}
+
+		m.Warehouses[i].EngineVersion = version
+		m.Warehouses[i].Error = ""
+		if err != nil {
+			m.Warehouses[i].Error = err.Error()
+		}
+	}
+}
+
+// Finish фиксирует итоговый статус и время завершения
+func (m *Manifest) Finish(status string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.FinishedAt = &now
+	m.Status = status
+
+	if err != nil {
+		m.Error = err.Error()
+	}
+}
+
+// Write сохраняет манифест в json; файл перезаписывается при каждом вызове
+func (m *Manifest) Write(path string) error {
+	m.mu.Lock()
+	data, err := json.MarshalIndent(m, "", "  ")
+	m.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации манифеста: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка записи манифеста: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/query"
+)
+
+func testConfig(password string) *config.Config {
+	return &config.Config{
+		Schema:      "tpcds_sf10",
+		Concurrency: 4,
+		Warehouses: []config.WarehouseConfig{
+			{
+				Name:    "trino",
+				Type:    "trino",
+				Enabled: true,
+				Connection: config.ConnectionConfig{
+					Host:     "trino.local",
+					Password: password,
+					// порядок ключей map не должен влиять на хеш
+					Properties: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "token": password},
+				},
+			},
+		},
+	}
+}
+
+func testQueries() []query.Query {
+	return []query.Query{
+		{ID: "q1", SQL: "select 1"},
+		{ID: "q2", SQL: "select 2"},
+	}
+}
+
+func newManifest(t *testing.T, cfg *config.Config, queries []query.Query) *Manifest {
+	t.Helper()
+
+	m, err := New("run", cfg, queries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestConfigHashStable(t *testing.T) {
+	base := newManifest(t, testConfig("secret"), testQueries()).ConfigHash
+
+	for range 10 {
+		if got := newManifest(t, testConfig("secret"), testQueries()).ConfigHash; got != base {
+			t.Fatalf("config hash changed between runs: %s != %s", got, base)
+		}
+	}
+
+	// пароли скрыты до хеширования
+	if got := newManifest(t, testConfig("other"), testQueries()).ConfigHash; got != base {
+		t.Errorf("config hash depends on password")
+	}
+
+	changed := testConfig("secret")
+	changed.Concurrency = 8
+	if got := newManifest(t, changed, testQueries()).ConfigHash; got == base {
+		t.Errorf("config hash ignores concurrency change")
+	}
+}
+
+func TestQuerySetHashStable(t *testing.T) {
+	cfg := testConfig("")
+	base := newManifest(t, cfg, testQueries()).QuerySetHash
+
+	if got := newManifest(t, cfg, testQueries()).QuerySetHash; got != base {
+		t.Fatalf("query set hash changed between runs")
+	}
+
+	reordered := testQueries()
+	reordered[0], reordered[1] = reordered[1], reordered[0]
+	if got := newManifest(t, cfg, reordered).QuerySetHash; got == base {
+		t.Errorf("query set hash ignores order")
+	}
+
+	edited := testQueries()
+	edited[1].SQL = "select 3"
+	if got := newManifest(t, cfg, edited).QuerySetHash; got == base {
+		t.Errorf("query set hash ignores sql change")
+	}
+
+	// запрос из файла хешируется по содержимому файла
+	path := filepath.Join(t.TempDir(), "q1.sql")
+	if err := os.WriteFile(path, []byte("select 1;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile := []query.Query{{ID: "q1", SQL: "select 1", Path: path}}
+	before := newManifest(t, cfg, fromFile)
+
+	if err := os.WriteFile(path, []byte("select 1; -- edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	after := newManifest(t, cfg, fromFile)
+	if before.Queries[0].SHA256 == after.Queries[0].SHA256 || before.QuerySetHash == after.QuerySetHash {
+		t.Errorf("query hash ignores file contents")
+	}
+}
+
+func TestResumeKeepsFirstStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run_manifest.json")
+
+	first := newManifest(t, testConfig(""), testQueries())
+	first.StartedAt = time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	first.Finish(StatusInterrupted, nil)
+	if err := first.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	second := newManifest(t, testConfig(""), testQueries())
+	secondStart := second.StartedAt
+	if err := second.Resume(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if !second.StartedAt.Equal(first.StartedAt) {
+		t.Errorf("started_at = %v, want first attempt %v", second.StartedAt, first.StartedAt)
+	}
+
+	if second.ResumedAt == nil || !second.ResumedAt.Equal(secondStart) {
+		t.Errorf("resumed_at = %v, want %v", second.ResumedAt, secondStart)
+	}
+
+	if len(second.PreviousAttempts) != 1 || second.PreviousAttempts[0].Status != StatusInterrupted ||
+		second.PreviousAttempts[0].ConfigHash != first.ConfigHash {
+		t.Fatalf("previous attempts: %+v", second.PreviousAttempts)
+	}
+
+	second.Finish(StatusFailed, nil)
+	if err := second.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// вторая попытка записана со своим началом, первая сохраняется
+	third := newManifest(t, testConfig(""), testQueries())
+	if err := third.Resume(path); err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := third.PreviousAttempts
+	if len(attempts) != 2 || !attempts[0].StartedAt.Equal(first.StartedAt) || !attempts[1].StartedAt.Equal(secondStart) ||
+		attempts[1].Status != StatusFailed {
+		t.Errorf("previous attempts after second resume: %+v", attempts)
+	}
+
+	if !third.StartedAt.Equal(first.StartedAt) {
+		t.Errorf("started_at drifted on second resume: %v", third.StartedAt)
+	}
+}
+
+func TestResumeWithoutManifest(t *testing.T) {
+	m := newManifest(t, testConfig(""), testQueries())
+	started := m.StartedAt
+
+	if err := m.Resume(filepath.Join(t.TempDir(), "missing_manifest.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.StartedAt.Equal(started) || m.ResumedAt != nil || len(m.PreviousAttempts) != 0 {
+		t.Errorf("missing manifest changed the new one: %+v", m)
+	}
+}
@@ -0,0 +1,36 @@
+package query
+
+import (
+	"strings"
+)
+
+// parseHeader читает комментарии "-- key: value" в начале файла,
+// до первой строки, которая не является комментарием
+func parseHeader(sql string) map[string]string {
+	header := make(map[string]string)
+
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+
+		key, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "--")), ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" || strings.ContainsAny(key, " \t") {
+			continue
+		}
+
+		header[key] = strings.TrimSpace(value)
+	}
+
+	return header
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -12,37 +13,89 @@ type Query struct {
 	ID   string
 	SQL  string
 	Path string
+
+	// Инструкции файла по порядку: двухчастные запросы TPC-DS (14, 23, 24, 39), скрипты
+	Statements []string
+
+	// Ожидаемое число строк из заголовка "-- expected_rows: N", nil если не задано
+	ExpectedRows *int
+
+	// Теги из заголовка "-- tags: a, b"
+	Tags []string
+}
+
+// HasAnyTag - запрос помечен хотя бы одним из тегов
+func (q Query) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, own := range q.Tags {
+			if strings.EqualFold(own, tag) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Selection - фильтры набора запросов, применяются по порядку:
+// явные ID, glob include, glob exclude, теги
+type Selection struct {
+	IDs     []string
+	Include []string
+	Exclude []string
+	Tags    []string
 }
 
 type QueryLoader struct {
-	queriesPath string
+	paths     []string
+	selection Selection
 }
 
 func NewQueryLoader(queriesPath string) *QueryLoader {
+	return NewSuiteLoader([]string{queriesPath}, Selection{})
+}
+
+// NewSuiteLoader загружает запросы из нескольких директорий с фильтрами
+func NewSuiteLoader(paths []string, selection Selection) *QueryLoader {
 	return &QueryLoader{
-		queriesPath: queriesPath,
+		paths:     paths,
+		selection: selection,
 	}
 }
 
 func (ql *QueryLoader) LoadAll() ([]Query, error) {
-	files, err := os.ReadDir(ql.queriesPath)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при чтении директории: %w", err)
-	}
-
 	var queries []Query
 
-	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".sql") {
-			continue
-		}
+	seen := make(map[string]string)
 
-		query, err := ql.loadQuery(file.Name())
+	for _, dir := range ql.paths {
+		files, err := os.ReadDir(dir)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load query %s: %s", file.Name(), err)
+			return nil, fmt.Errorf("ошибка при чтении директории: %w", err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".sql") {
+				continue
+			}
+
+			query, err := ql.loadQuery(dir, file.Name())
+			if err != nil {
+				return nil, fmt.Errorf("failed to load query %s: %s", file.Name(), err)
+			}
+
+			if other, ok := seen[query.ID]; ok {
+				return nil, fmt.Errorf("запрос %s есть в %s и %s", query.ID, other, dir)
+			}
+			seen[query.ID] = dir
+
+			queries = append(queries, query)
 		}
+	}
 
-		queries = append(queries, query)
+	queries, err := ql.selection.apply(queries)
+	if err != nil {
+		return nil, err
 	}
 
 	sort.Slice(queries, func(i, j int) bool {
@@ -53,8 +106,57 @@ func (ql *QueryLoader) LoadAll() ([]Query, error) {
 
 }
 
-func (ql *QueryLoader) loadQuery(filename string) (Query, error) {
-	path := filepath.Join(ql.queriesPath, filename)
+func (s Selection) apply(queries []Query) ([]Query, error) {
+	if len(s.IDs) > 0 {
+		byID := make(map[string]Query, len(queries))
+		for _, q := range queries {
+			byID[q.ID] = q
+		}
+
+		queries = nil
+		for _, id := range s.IDs {
+			q, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("запрос %s из списка queries не найден", id)
+			}
+
+			queries = append(queries, q)
+		}
+	}
+
+	var selected []Query
+
+	for _, q := range queries {
+		if len(s.Include) > 0 && !matchAny(s.Include, q.ID) {
+			continue
+		}
+
+		if matchAny(s.Exclude, q.ID) {
+			continue
+		}
+
+		if len(s.Tags) > 0 && !q.HasAnyTag(s.Tags) {
+			continue
+		}
+
+		selected = append(selected, q)
+	}
+
+	return selected, nil
+}
+
+func matchAny(patterns []string, id string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, id); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ql *QueryLoader) loadQuery(dir, filename string) (Query, error) {
+	path := filepath.Join(dir, filename)
 
 	content, err := os.ReadFile(path)
 
@@ -64,9 +166,34 @@ func (ql *QueryLoader) loadQuery(filename string) (Query, error) {
 
 	id := strings.TrimSuffix(filename, ".sql")
 
-	return Query{
-		ID:   id,
-		SQL:  string(content),
-		Path: path,
-	}, nil
+	q := Query{
+		ID:         id,
+		SQL:        string(content),
+		Path:       path,
+		Statements: SplitStatements(string(content)),
+	}
+
+	if len(q.Statements) == 0 {
+		return Query{}, fmt.Errorf("в файле нет инструкций")
+	}
+
+	header := parseHeader(q.SQL)
+
+	if value, ok := header["expected_rows"]; ok {
+		rows, err := strconv.Atoi(value)
+		if err != nil {
+			return Query{}, fmt.Errorf("неверный expected_rows %q: %w", value, err)
+		}
+		q.ExpectedRows = &rows
+	}
+
+	if value, ok := header["tags"]; ok {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				q.Tags = append(q.Tags, tag)
+			}
+		}
+	}
+
+	return q, nil
 }
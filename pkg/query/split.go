@@ -0,0 +1,114 @@
+package query
+
+import (
+	"strings"
+)
+
+// SplitStatements делит текст на инструкции по ";" вне строковых литералов,
+// идентификаторов в кавычках и комментариев. Инструкции без кода
+// (только комментарии, например "-- end query 14") отбрасываются.
+// Кавычка в литерале экранируется удвоением, как в стандарте SQL (trino, vertica)
+func SplitStatements(sql string) []string {
+	return splitStatements(sql, false)
+}
+
+// SplitStatementsBackslash - SplitStatements для движков, где в строковых
+// литералах действует экранирование обратным слэшем: 'it\'s'
+func SplitStatementsBackslash(sql string) []string {
+	return splitStatements(sql, true)
+}
+
+// BackslashEscapes - движок warehouseType понимает \' внутри строковых литералов
+func BackslashEscapes(warehouseType string) bool {
+	switch warehouseType {
+	case "hive", "spark", "impala":
+		return true
+	default:
+		return false
+	}
+}
+
+// StatementsFor - инструкции запроса с правилами экранирования движка warehouseType
+func (q Query) StatementsFor(warehouseType string) []string {
+	if !BackslashEscapes(warehouseType) || !strings.Contains(q.SQL, `\`) {
+		return q.Statements
+	}
+
+	return SplitStatementsBackslash(q.SQL)
+}
+
+func splitStatements(sql string, backslash bool) []string {
+	var statements []string
+
+	start := 0
+	hasCode := false
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		switch {
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+			}
+
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+
+		case c == '\'' || c == '"' || c == '`':
+			hasCode = true
+			i = skipQuoted(sql, i, backslash && c != '`')
+
+		case c == ';':
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(sql[start:i]))
+			}
+
+			start = i + 1
+			hasCode = false
+
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			hasCode = true
+		}
+	}
+
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(sql[start:]))
+	}
+
+	return statements
+}
+
+// skipQuoted возвращает позицию закрывающей кавычки; удвоенная кавычка - экранирование,
+// при backslash обратный слэш экранирует следующий символ
+func skipQuoted(sql string, open int, backslash bool) int {
+	quote := sql[open]
+
+	for i := open + 1; i < len(sql); i++ {
+		if backslash && sql[i] == '\\' {
+			i++
+			continue
+		}
+
+		if sql[i] != quote {
+			continue
+		}
+
+		if i+1 < len(sql) && sql[i+1] == quote {
+			i++
+			continue
+		}
+
+		return i
+	}
+
+	return len(sql)
+}
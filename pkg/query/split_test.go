@@ -0,0 +1,163 @@
+package query
+
+import (
+	"slices"
+	"testing"
+)
+
+// две части в духе TPC-DS 14, 23, 24, 39: общий заголовок, комментарии между инструкциями
+const twoPartQuery = `-- start query 39 in stream 0 using template query39.tpl
+with inv as (select w_warehouse_name, i_item_sk from inventory)
+select * from inv where i_item_sk = 1
+order by w_warehouse_name;
+with inv as (select w_warehouse_name, i_item_sk from inventory)
+select * from inv where i_item_sk = 2
+order by w_warehouse_name;
+
+-- end query 39 in stream 0 using template query39.tpl
+`
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "single with trailing semicolon",
+			sql:  "select 1;\n",
+			want: []string{"select 1"},
+		},
+		{
+			name: "last statement without semicolon",
+			sql:  "select 1; select 2",
+			want: []string{"select 1", "select 2"},
+		},
+		{
+			name: "semicolon in string literal",
+			sql:  "select 'a;b' from t; select 2",
+			want: []string{"select 'a;b' from t", "select 2"},
+		},
+		{
+			name: "doubled quote in literal",
+			sql:  "select 'it''s; fine'; select 2",
+			want: []string{"select 'it''s; fine'", "select 2"},
+		},
+		{
+			name: "semicolon in quoted identifiers",
+			sql:  "select \"a;b\", `c;d` from t; select 2",
+			want: []string{"select \"a;b\", `c;d` from t", "select 2"},
+		},
+		{
+			name: "semicolon in line comment",
+			sql:  "select 1 -- not here; really\n; select 2",
+			want: []string{"select 1 -- not here; really", "select 2"},
+		},
+		{
+			name: "semicolon in block comment",
+			sql:  "select /* a; b */ 1; select 2",
+			want: []string{"select /* a; b */ 1", "select 2"},
+		},
+		{
+			name: "comment-only tail dropped",
+			sql:  "select 1;\n-- end query 1\n",
+			want: []string{"select 1"},
+		},
+		{
+			name: "empty statements dropped",
+			sql:  ";; select 1;;",
+			want: []string{"select 1"},
+		},
+		{
+			name: "unterminated literal keeps rest",
+			sql:  "select 'a; select 2",
+			want: []string{"select 'a; select 2"},
+		},
+		{
+			name: "backslash is literal in standard mode",
+			sql:  `select 'C:\'; select 2`,
+			want: []string{`select 'C:\'`, "select 2"},
+		},
+		{
+			name: "two-part tpc-ds query",
+			sql:  twoPartQuery,
+			want: []string{
+				"-- start query 39 in stream 0 using template query39.tpl\n" +
+					"with inv as (select w_warehouse_name, i_item_sk from inventory)\n" +
+					"select * from inv where i_item_sk = 1\n" +
+					"order by w_warehouse_name",
+				"with inv as (select w_warehouse_name, i_item_sk from inventory)\n" +
+					"select * from inv where i_item_sk = 2\n" +
+					"order by w_warehouse_name",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatements(tt.sql)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SplitStatements(%q)\n got: %q\nwant: %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitStatementsBackslash(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "escaped quote before semicolon",
+			sql:  `select 'it\';s' from t; select 2`,
+			want: []string{`select 'it\';s' from t`, "select 2"},
+		},
+		{
+			name: "escaped backslash closes literal",
+			sql:  `select 'C:\\'; select 2`,
+			want: []string{`select 'C:\\'`, "select 2"},
+		},
+		{
+			name: "double quoted literal",
+			sql:  `select "a\";b"; select 2`,
+			want: []string{`select "a\";b"`, "select 2"},
+		},
+		{
+			name: "backticks do not use backslash",
+			sql:  "select `a\\`; select 2",
+			want: []string{"select `a\\`", "select 2"},
+		},
+		{
+			name: "doubled quote still works",
+			sql:  "select 'it''s; fine'; select 2",
+			want: []string{"select 'it''s; fine'", "select 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatementsBackslash(tt.sql)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SplitStatementsBackslash(%q)\n got: %q\nwant: %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementsFor(t *testing.T) {
+	sql := `select 'it\';s'; select 2`
+	q := Query{SQL: sql, Statements: SplitStatements(sql)}
+
+	if got := q.StatementsFor("trino"); !slices.Equal(got, q.Statements) {
+		t.Errorf("trino: got %q, want loader split %q", got, q.Statements)
+	}
+
+	want := []string{`select 'it\';s'`, "select 2"}
+	for _, typ := range []string{"hive", "spark", "impala"} {
+		if got := q.StatementsFor(typ); !slices.Equal(got, want) {
+			t.Errorf("%s: got %q, want %q", typ, got, want)
+		}
+	}
+}
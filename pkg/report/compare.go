@@ -0,0 +1,355 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"tpcds_benchmark/pkg/stats"
+	"tpcds_benchmark/pkg/storage"
+)
+
+const (
+	VerdictRegression  = "regression"
+	VerdictImprovement = "improvement"
+	VerdictUnchanged   = "unchanged"
+	VerdictNewFailure  = "new_failure"
+	VerdictFixed       = "fixed"
+	VerdictNoData      = "no_data"
+
+	// запрос есть только в одном из наборов
+	VerdictMissing = "missing"
+	VerdictAdded   = "added"
+)
+
+type CompareOptions struct {
+	// относительное изменение медианы, начиная с которого это регрессия или улучшение
+	Threshold float64
+
+	// уровень доверительного интервала и число итераций бутстрепа
+	Confidence float64
+	Iterations int
+	Seed       int64
+}
+
+// Comparison - сравнение кандидата с базовым набором результатов
+type Comparison struct {
+	Baseline  string         `json:"baseline"`
+	Candidate string         `json:"candidate"`
+	Options   CompareOptions `json:"options"`
+
+	Warehouses []WarehouseChange `json:"warehouses"`
+	Queries    []QueryChange     `json:"queries"`
+}
+
+// QueryChange - изменение запроса на хранилище; медианы в ms по успешным запускам
+type QueryChange struct {
+	Warehouse string `json:"warehouse"`
+	QueryID   string `json:"query_id"`
+	Verdict   string `json:"verdict"`
+
+	BaselineMedian  float64 `json:"baseline_median_ms,omitempty"`
+	CandidateMedian float64 `json:"candidate_median_ms,omitempty"`
+
+	// candidate/baseline - 1 и его доверительный интервал
+	Change float64 `json:"change,omitempty"`
+	CILow  float64 `json:"ci_low,omitempty"`
+	CIHigh float64 `json:"ci_high,omitempty"`
+
+	BaselineRuns    int `json:"baseline_runs"`
+	CandidateRuns   int `json:"candidate_runs"`
+	BaselineFailed  int `json:"baseline_failed"`
+	CandidateFailed int `json:"candidate_failed"`
+}
+
+// WarehouseChange - итог сравнения по хранилищу
+type WarehouseChange struct {
+	Warehouse    string `json:"warehouse"`
+	Matched      int    `json:"matched"`
+	Regressions  int    `json:"regressions"`
+	Improvements int    `json:"improvements"`
+	NewFailures  int    `json:"new_failures"`
+
+	// среднее геометрическое отношений медиан - 1
+	GeomeanChange float64 `json:"geomean_change"`
+}
+
+// Regressed - есть регрессии или новые ошибки
+func (c Comparison) Regressed() bool {
+	for _, q := range c.Queries {
+		if q.Verdict == VerdictRegression || q.Verdict == VerdictNewFailure {
+			return true
+		}
+	}
+
+	return false
+}
+
+type runSample struct {
+	durations []float64
+	failed    int
+}
+
+func samples(results []storage.BenchmarkResult) ([]queryKey, map[queryKey]*runSample) {
+	var keys []queryKey
+	byKey := make(map[queryKey]*runSample)
+
+	for _, r := range results {
+		key := queryKey{r.Warehouse, r.QueryID}
+
+		s, ok := byKey[key]
+		if !ok {
+			s = &runSample{}
+			byKey[key] = s
+			keys = append(keys, key)
+		}
+
+		switch r.Status {
+		case storage.StatusSuccess:
+			// длительности меньше 1 ms считаются за 1, чтобы отношение медиан было определено
+			s.durations = append(s.durations, math.Max(float64(r.DurationMs), 1))
+		case storage.StatusError:
+			s.failed++
+		}
+	}
+
+	return keys, byKey
+}
+
+// Compare сопоставляет результаты по хранилищу и запросу
+func Compare(baselineName string, baseline []storage.BenchmarkResult, candidateName string, candidate []storage.BenchmarkResult, opts CompareOptions) Comparison {
+	c := Comparison{
+		Baseline:  baselineName,
+		Candidate: candidateName,
+		Options:   opts,
+	}
+
+	rnd := rand.New(rand.NewSource(opts.Seed))
+
+	baseKeys, base := samples(baseline)
+	candKeys, cand := samples(candidate)
+
+	keys := baseKeys
+	for _, key := range candKeys {
+		if base[key] == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		c.Queries = append(c.Queries, compareQuery(key, base[key], cand[key], opts, rnd))
+	}
+
+	var (
+		warehouses []string
+		ratios     = make(map[string][]float64)
+		totals     = make(map[string]*WarehouseChange)
+	)
+
+	for _, q := range c.Queries {
+		wc, ok := totals[q.Warehouse]
+		if !ok {
+			wc = &WarehouseChange{Warehouse: q.Warehouse}
+			totals[q.Warehouse] = wc
+			warehouses = append(warehouses, q.Warehouse)
+		}
+
+		switch q.Verdict {
+		case VerdictRegression:
+			wc.Regressions++
+		case VerdictImprovement:
+			wc.Improvements++
+		case VerdictNewFailure:
+			wc.NewFailures++
+		}
+
+		if q.BaselineRuns > 0 && q.CandidateRuns > 0 {
+			wc.Matched++
+			ratios[q.Warehouse] = append(ratios[q.Warehouse], q.CandidateMedian/q.BaselineMedian)
+		}
+	}
+
+	for _, wh := range warehouses {
+		wc := totals[wh]
+		if len(ratios[wh]) > 0 {
+			wc.GeomeanChange = geomeanRatio(ratios[wh]) - 1
+		}
+
+		c.Warehouses = append(c.Warehouses, *wc)
+	}
+
+	return c
+}
+
+func compareQuery(key queryKey, base, cand *runSample, opts CompareOptions, rnd *rand.Rand) QueryChange {
+	q := QueryChange{Warehouse: key.warehouse, QueryID: key.queryID}
+
+	switch {
+	case cand == nil:
+		q.Verdict = VerdictMissing
+		q.BaselineRuns, q.BaselineFailed = len(base.durations), base.failed
+		return q
+	case base == nil:
+		q.Verdict = VerdictAdded
+		q.CandidateRuns, q.CandidateFailed = len(cand.durations), cand.failed
+		return q
+	}
+
+	q.BaselineRuns, q.BaselineFailed = len(base.durations), base.failed
+	q.CandidateRuns, q.CandidateFailed = len(cand.durations), cand.failed
+
+	if q.BaselineRuns > 0 && q.CandidateRuns > 0 {
+		q.BaselineMedian = stats.Percentile(stats.Sorted(base.durations), 50)
+		q.CandidateMedian = stats.Percentile(stats.Sorted(cand.durations), 50)
+		q.Change = q.CandidateMedian/q.BaselineMedian - 1
+		q.CILow, q.CIHigh = stats.MedianChangeCI(base.durations, cand.durations, opts.Iterations, opts.Confidence, rnd)
+	}
+
+	switch {
+	case q.CandidateFailed > 0 && q.BaselineFailed == 0:
+		q.Verdict = VerdictNewFailure
+	case q.BaselineFailed > 0 && q.CandidateFailed == 0 && q.CandidateRuns > 0:
+		q.Verdict = VerdictFixed
+	case q.BaselineRuns == 0 || q.CandidateRuns == 0:
+		q.Verdict = VerdictNoData
+	// изменение больше порога и интервал не захватывает ноль
+	case q.Change > opts.Threshold && q.CILow > 0:
+		q.Verdict = VerdictRegression
+	case q.Change < -opts.Threshold && q.CIHigh < 0:
+		q.Verdict = VerdictImprovement
+	default:
+		q.Verdict = VerdictUnchanged
+	}
+
+	return q
+}
+
+func geomeanRatio(ratios []float64) float64 {
+	sum := 0.0
+	for _, r := range ratios {
+		sum += math.Log(r)
+	}
+
+	return math.Exp(sum / float64(len(ratios)))
+}
+
+var CompareFormats = []string{"table", "markdown", "json"}
+
+// WriteComparison выводит сравнение в одном из CompareFormats
+func WriteComparison(w io.Writer, c Comparison, format string) error {
+	switch format {
+	case "table":
+		return writeComparisonTable(w, c)
+	case "markdown":
+		return writeComparisonMarkdown(w, c)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	default:
+		return fmt.Errorf("неизвестный формат сравнения: %s (доступны: %s)", format, strings.Join(CompareFormats, ", "))
+	}
+}
+
+var compareColumns = []string{"warehouse", "query_id", "verdict", "baseline_ms", "candidate_ms", "change", "ci", "runs", "failed"}
+
+func compareRow(q QueryChange) []string {
+	row := []string{q.Warehouse, q.QueryID, q.Verdict, "-", "-", "-", "-"}
+
+	if q.BaselineRuns > 0 && q.CandidateRuns > 0 {
+		row[3] = ms(q.BaselineMedian)
+		row[4] = ms(q.CandidateMedian)
+		row[5] = signedPercent(q.Change)
+		row[6] = fmt.Sprintf("[%s, %s]", signedPercent(q.CILow), signedPercent(q.CIHigh))
+	}
+
+	return append(row,
+		fmt.Sprintf("%d/%d", q.BaselineRuns, q.CandidateRuns),
+		fmt.Sprintf("%d/%d", q.BaselineFailed, q.CandidateFailed),
+	)
+}
+
+var warehouseCompareColumns = []string{"warehouse", "matched", "regressions", "improvements", "new_failures", "geomean_change"}
+
+func warehouseCompareRow(wc WarehouseChange) []string {
+	geomean := "-"
+	if wc.Matched > 0 {
+		geomean = signedPercent(wc.GeomeanChange)
+	}
+
+	return []string{
+		wc.Warehouse,
+		strconv.Itoa(wc.Matched),
+		strconv.Itoa(wc.Regressions),
+		strconv.Itoa(wc.Improvements),
+		strconv.Itoa(wc.NewFailures),
+		geomean,
+	}
+}
+
+func signedPercent(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+
+	return fmt.Sprintf("%+.1f%%", v*100)
+}
+
+func writeComparisonTable(w io.Writer, c Comparison) error {
+	fmt.Fprintf(w, "базовый: %s\nкандидат: %s\nпорог: %.1f%%, доверие: %.0f%%\n\n",
+		c.Baseline, c.Candidate, c.Options.Threshold*100, c.Options.Confidence*100)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	writeTabRow(tw, upper(compareColumns))
+	for _, q := range c.Queries {
+		writeTabRow(tw, compareRow(q))
+	}
+
+	fmt.Fprintln(tw)
+
+	writeTabRow(tw, upper(warehouseCompareColumns))
+	for _, wc := range c.Warehouses {
+		writeTabRow(tw, warehouseCompareRow(wc))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, verdict := range []string{VerdictRegression, VerdictNewFailure} {
+		var items []string
+		for _, q := range c.Queries {
+			if q.Verdict == verdict {
+				items = append(items, q.Warehouse+"/"+q.QueryID)
+			}
+		}
+
+		if len(items) > 0 {
+			fmt.Fprintf(w, "\n%s (%d): %s\n", verdict, len(items), strings.Join(items, ", "))
+		}
+	}
+
+	return nil
+}
+
+func writeComparisonMarkdown(w io.Writer, c Comparison) error {
+	fmt.Fprintf(w, "# Сравнение: %s → %s\n\n", c.Baseline, c.Candidate)
+	fmt.Fprintf(w, "Порог %.1f%%, доверительный интервал %.0f%%.\n\n", c.Options.Threshold*100, c.Options.Confidence*100)
+
+	fmt.Fprintf(w, "## Хранилища\n\n")
+	writeMarkdownTable(w, warehouseCompareColumns, len(c.Warehouses), func(i int) []string {
+		return warehouseCompareRow(c.Warehouses[i])
+	})
+
+	fmt.Fprintf(w, "\n## Запросы\n\n")
+	writeMarkdownTable(w, compareColumns, len(c.Queries), func(i int) []string {
+		return compareRow(c.Queries[i])
+	})
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"math"
+	"testing"
+	"tpcds_benchmark/pkg/storage"
+)
+
+var compareOpts = CompareOptions{Threshold: 0.1, Confidence: 0.95, Iterations: 1000, Seed: 1}
+
+// durations - успешные запуски запроса на хранилище a
+func durations(queryID string, values ...int) []storage.BenchmarkResult {
+	var results []storage.BenchmarkResult
+	for _, v := range values {
+		results = append(results, run("a", queryID, storage.StatusSuccess, 0, v))
+	}
+
+	return results
+}
+
+func failures(queryID string, n int) []storage.BenchmarkResult {
+	var results []storage.BenchmarkResult
+	for i := 0; i < n; i++ {
+		results = append(results, run("a", queryID, storage.StatusError, 0, 10))
+	}
+
+	return results
+}
+
+func concat(groups ...[]storage.BenchmarkResult) []storage.BenchmarkResult {
+	var out []storage.BenchmarkResult
+	for _, g := range groups {
+		out = append(out, g...)
+	}
+
+	return out
+}
+
+func verdicts(c Comparison) map[string]string {
+	out := make(map[string]string)
+	for _, q := range c.Queries {
+		out[q.QueryID] = q.Verdict
+	}
+
+	return out
+}
+
+func TestCompareVerdicts(t *testing.T) {
+	baseline := concat(
+		durations("regressed", 98, 99, 100, 101, 102),
+		durations("improved", 198, 199, 200, 201, 202),
+		durations("same", 99, 100, 101, 100, 100),
+
+		// +30% по медиане, но три разбросанных запуска - интервал захватывает ноль
+		durations("noisy", 50, 100, 400),
+
+		durations("broken", 100, 100, 100),
+		durations("fixed", 100),
+		failures("fixed", 2),
+		[]storage.BenchmarkResult{run("a", "not_run", storage.StatusNotRun, 0, 0)},
+		durations("dropped", 100),
+	)
+
+	candidate := concat(
+		durations("regressed", 148, 149, 150, 151, 152),
+		durations("improved", 98, 99, 100, 101, 102),
+		durations("same", 101, 102, 103, 102, 102),
+		durations("noisy", 60, 130, 350),
+		durations("broken", 100, 100),
+		failures("broken", 1),
+		durations("fixed", 90, 95),
+		durations("not_run", 100),
+		durations("new", 100),
+	)
+
+	c := Compare("base", baseline, "cand", candidate, compareOpts)
+
+	want := map[string]string{
+		"regressed": VerdictRegression,
+		"improved":  VerdictImprovement,
+		"same":      VerdictUnchanged,
+		"noisy":     VerdictUnchanged,
+		"broken":    VerdictNewFailure,
+		"fixed":     VerdictFixed,
+		"not_run":   VerdictNoData,
+		"dropped":   VerdictMissing,
+		"new":       VerdictAdded,
+	}
+
+	got := verdicts(c)
+	for id, verdict := range want {
+		if got[id] != verdict {
+			t.Errorf("%s: verdict %q, want %q", id, got[id], verdict)
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("unexpected queries: %v", got)
+	}
+
+	// порядок - базовые запросы, затем новые из кандидата
+	if last := c.Queries[len(c.Queries)-1]; last.QueryID != "new" {
+		t.Errorf("added query should come last, got %s", last.QueryID)
+	}
+
+	if !c.Regressed() {
+		t.Error("Regressed() = false with a regression and a new failure")
+	}
+}
+
+func TestCompareChangeAndTotals(t *testing.T) {
+	baseline := concat(durations("q1", 100, 100, 100), durations("q2", 400, 400, 400))
+	candidate := concat(durations("q1", 200, 200, 200), durations("q2", 100, 100, 100))
+
+	c := Compare("base", baseline, "cand", candidate, compareOpts)
+
+	q1 := c.Queries[0]
+	if q1.BaselineMedian != 100 || q1.CandidateMedian != 200 || !near(q1.Change, 1) || !near(q1.CILow, 1) || !near(q1.CIHigh, 1) {
+		t.Errorf("q1: %+v", q1)
+	}
+
+	if len(c.Warehouses) != 1 {
+		t.Fatalf("warehouses: %+v", c.Warehouses)
+	}
+
+	// отношения медиан 2 и 0.25: среднее геометрическое sqrt(0.5)
+	wc := c.Warehouses[0]
+	if wc.Matched != 2 || wc.Regressions != 1 || wc.Improvements != 1 || !near(wc.GeomeanChange, math.Sqrt(0.5)-1) {
+		t.Errorf("totals: %+v", wc)
+	}
+}
+
+func TestCompareSubMillisecondDurations(t *testing.T) {
+	// длительности 0 ms считаются за 1 ms, отношение медиан определено
+	c := Compare("base", durations("q1", 0, 0, 0), "cand", durations("q1", 0, 1, 0), compareOpts)
+
+	q := c.Queries[0]
+	if q.Verdict != VerdictUnchanged || q.Change != 0 || math.IsNaN(q.CILow) {
+		t.Errorf("q1: %+v", q)
+	}
+}
+
+func TestCompareNotRegressed(t *testing.T) {
+	baseline := concat(durations("q1", 200, 201, 199), failures("q2", 1))
+	candidate := concat(durations("q1", 100, 101, 99), durations("q2", 50))
+
+	c := Compare("base", baseline, "cand", candidate, compareOpts)
+	if c.Regressed() {
+		t.Errorf("Regressed() = true for improvement and fix: %v", verdicts(c))
+	}
+}
+
+func TestCompareDeterministic(t *testing.T) {
+	baseline := durations("q1", 50, 80, 100, 120, 160)
+	candidate := durations("q1", 60, 90, 110, 140, 170)
+
+	first := Compare("base", baseline, "cand", candidate, compareOpts)
+	second := Compare("base", baseline, "cand", candidate, compareOpts)
+
+	if first.Queries[0].CILow != second.Queries[0].CILow || first.Queries[0].CIHigh != second.Queries[0].CIHigh {
+		t.Errorf("same seed gave different intervals: %+v vs %+v", first.Queries[0], second.Queries[0])
+	}
+}
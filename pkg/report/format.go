@@ -0,0 +1,240 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var Formats = []string{"table", "csv", "json", "markdown", "html"}
+
+// Write выводит отчет в одном из Formats
+func Write(w io.Writer, s Summary, format string) error {
+	switch format {
+	case "table":
+		return WriteTable(w, s)
+	case "csv":
+		return WriteCSV(w, s)
+	case "json":
+		return WriteJSON(w, s)
+	case "markdown":
+		return WriteMarkdown(w, s)
+	case "html":
+		return WriteHTML(w, s)
+	default:
+		return fmt.Errorf("неизвестный формат отчета: %s (доступны: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// SaveFiles сохраняет отчет рядом с результатами: base_report.csv, .json, .md и .html
+func SaveFiles(base string, s Summary) ([]string, error) {
+	files := []struct {
+		path   string
+		format string
+	}{
+		{base + "_report.csv", "csv"},
+		{base + "_report.json", "json"},
+		{base + "_report.md", "markdown"},
+		{base + "_report.html", "html"},
+	}
+
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return nil, fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	var paths []string
+
+	for _, f := range files {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return paths, fmt.Errorf("ошибка при создании файла: %w", err)
+		}
+
+		if err := Write(file, s, f.format); err != nil {
+			file.Close()
+			return paths, fmt.Errorf("ошибка записи отчета %s: %w", f.path, err)
+		}
+
+		if err := file.Close(); err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, f.path)
+	}
+
+	return paths, nil
+}
+
+var queryColumns = []string{"warehouse", "query_id", "count", "success_rate", "min_ms", "median_ms", "mean_ms", "p90_ms", "p95_ms", "p99_ms", "max_ms", "stddev_ms", "cv"}
+
+var warehouseColumns = []string{"warehouse", "queries", "count", "succeeded", "failed", "skipped", "success_rate", "total_ms", "wall_ms", "geomean_ms"}
+
+// queryRow - строка статистики запроса; ms округляются, для запроса без успешных запусков "-"
+func queryRow(qs QueryStats, rate func(float64) string) []string {
+	row := []string{qs.Warehouse, qs.QueryID, strconv.Itoa(qs.Count), rate(qs.SuccessRate)}
+
+	l := qs.Latency
+	if l == nil {
+		for range 9 {
+			row = append(row, "-")
+		}
+		return row
+	}
+
+	for _, v := range []float64{l.Min, l.Median, l.Mean, l.P90, l.P95, l.P99, l.Max, l.StdDev} {
+		row = append(row, ms(v))
+	}
+
+	return append(row, strconv.FormatFloat(l.CV, 'f', 3, 64))
+}
+
+func warehouseRow(ws WarehouseStats, rate func(float64) string) []string {
+	geomean := "-"
+	if ws.Succeeded > 0 {
+		geomean = ms(ws.GeomeanMs)
+	}
+
+	return []string{
+		ws.Warehouse,
+		strconv.Itoa(ws.Queries),
+		strconv.Itoa(ws.Count),
+		strconv.Itoa(ws.Succeeded),
+		strconv.Itoa(ws.Failed),
+		strconv.Itoa(ws.Skipped),
+		rate(ws.SuccessRate),
+		ms(ws.TotalMs),
+		ms(ws.WallMs),
+		geomean,
+	}
+}
+
+func ms(v float64) string {
+	return strconv.FormatFloat(v, 'f', 0, 64)
+}
+
+func percent(v float64) string {
+	return strconv.FormatFloat(v*100, 'f', 1, 64) + "%"
+}
+
+func fraction(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func WriteTable(w io.Writer, s Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	writeTabRow(tw, upper(queryColumns))
+	for _, qs := range s.Queries {
+		writeTabRow(tw, queryRow(qs, percent))
+	}
+
+	fmt.Fprintln(tw)
+
+	writeTabRow(tw, upper(warehouseColumns))
+	for _, ws := range s.Warehouses {
+		writeTabRow(tw, warehouseRow(ws, percent))
+	}
+
+	return tw.Flush()
+}
+
+func writeTabRow(w io.Writer, cells []string) {
+	fmt.Fprintln(w, strings.Join(cells, "\t")+"\t")
+}
+
+func upper(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToUpper(v)
+	}
+	return out
+}
+
+// WriteCSV - одна таблица: scope=query для запросов и scope=warehouse для итогов хранилищ,
+// пустые ячейки - нет значения
+func WriteCSV(w io.Writer, s Summary) error {
+	cw := csv.NewWriter(w)
+
+	header := append([]string{"scope"}, queryColumns...)
+	cw.Write(append(header, "succeeded", "failed", "skipped", "total_ms", "wall_ms", "geomean_ms"))
+
+	for _, qs := range s.Queries {
+		row := append([]string{"query"}, queryRow(qs, fraction)...)
+		for i := range row {
+			if row[i] == "-" {
+				row[i] = ""
+			}
+		}
+
+		cw.Write(append(row, strconv.Itoa(qs.Succeeded), strconv.Itoa(qs.Failed), strconv.Itoa(qs.Skipped), "", "", ""))
+	}
+
+	for _, ws := range s.Warehouses {
+		geomean := ""
+		if ws.Succeeded > 0 {
+			geomean = ms(ws.GeomeanMs)
+		}
+
+		row := []string{"warehouse", ws.Warehouse, "", strconv.Itoa(ws.Count), fraction(ws.SuccessRate)}
+		row = append(row, make([]string, 9)...)
+
+		cw.Write(append(row,
+			strconv.Itoa(ws.Succeeded),
+			strconv.Itoa(ws.Failed),
+			strconv.Itoa(ws.Skipped),
+			ms(ws.TotalMs),
+			ms(ws.WallMs),
+			geomean,
+		))
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func WriteJSON(w io.Writer, s Summary) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+func WriteMarkdown(w io.Writer, s Summary) error {
+	fmt.Fprintf(w, "# Отчет: %s\n\n", s.Source)
+
+	fmt.Fprintf(w, "## Хранилища\n\n")
+	writeMarkdownTable(w, warehouseColumns, len(s.Warehouses), func(i int) []string {
+		return warehouseRow(s.Warehouses[i], percent)
+	})
+
+	fmt.Fprintf(w, "\n## Запросы\n\n")
+	writeMarkdownTable(w, queryColumns, len(s.Queries), func(i int) []string {
+		return queryRow(s.Queries[i], percent)
+	})
+
+	return nil
+}
+
+func writeMarkdownTable(w io.Writer, header []string, n int, row func(i int) []string) {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+
+	align := make([]string, len(header))
+	for i := range align {
+		align[i] = "---:"
+	}
+	align[0] = "---"
+	fmt.Fprintf(w, "|%s|\n", strings.Join(align, "|"))
+
+	for i := 0; i < n; i++ {
+		cells := row(i)
+		for j, c := range cells {
+			cells[j] = strings.ReplaceAll(c, "|", `\|`)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
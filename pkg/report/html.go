@@ -0,0 +1,185 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlReport - данные страницы; графики - готовый inline svg
+type htmlReport struct {
+	Source    string
+	Generated string
+
+	Legend     []legendItem
+	Warehouses []WarehouseStats
+	Queries    []QueryStats
+
+	MedianBars   template.HTML
+	LatencyBoxes template.HTML
+	Timelines    []timeline
+	Failures     failureMatrix
+}
+
+type legendItem struct {
+	Name  string
+	Color string
+}
+
+type timeline struct {
+	Warehouse string
+	SVG       template.HTML
+}
+
+// failureMatrix - запросы с ошибками или пропусками по хранилищам
+type failureMatrix struct {
+	Warehouses []string
+	Rows       []failureRow
+}
+
+type failureRow struct {
+	QueryID string
+	Cells   []failureCell
+}
+
+type failureCell struct {
+	Text  string
+	Title string
+	Style template.CSS
+}
+
+func newFailureMatrix(s Summary) failureMatrix {
+	m := failureMatrix{}
+	for _, ws := range s.Warehouses {
+		m.Warehouses = append(m.Warehouses, ws.Warehouse)
+	}
+
+	for _, id := range queryIDs(s) {
+		row := failureRow{QueryID: id}
+		problems := false
+
+		for _, ws := range s.Warehouses {
+			qs := s.query(ws.Warehouse, id)
+			if qs == nil {
+				row.Cells = append(row.Cells, failureCell{Text: "-"})
+				continue
+			}
+
+			if qs.Failed > 0 || qs.Skipped > 0 {
+				problems = true
+			}
+
+			cell := failureCell{
+				Text:  fmt.Sprintf("%d/%d", qs.Failed, qs.Count),
+				Title: fmt.Sprintf("ошибок %d, пропущено %d из %d", qs.Failed, qs.Skipped, qs.Count),
+			}
+
+			switch {
+			case qs.Failed > 0:
+				alpha := 0.2 + 0.8*float64(qs.Failed)/float64(qs.Count)
+				cell.Style = template.CSS(fmt.Sprintf("background: rgba(225, 87, 89, %.2f)", alpha))
+			case qs.Skipped > 0:
+				cell.Style = template.CSS("background: " + colorSkipped)
+			}
+
+			row.Cells = append(row.Cells, cell)
+		}
+
+		if problems {
+			m.Rows = append(m.Rows, row)
+		}
+	}
+
+	return m
+}
+
+// WriteHTML - самодостаточная страница без внешних ресурсов: таблицы, графики медиан,
+// распределения длительностей, диаграммы потоков и матрица ошибок
+func WriteHTML(w io.Writer, s Summary) error {
+	page := htmlReport{
+		Source:       s.Source,
+		Generated:    time.Now().Format(time.DateTime),
+		Warehouses:   s.Warehouses,
+		Queries:      s.Queries,
+		MedianBars:   template.HTML(medianBars(s)),
+		LatencyBoxes: template.HTML(latencyBoxes(s)),
+		Failures:     newFailureMatrix(s),
+	}
+
+	for i, ws := range s.Warehouses {
+		page.Legend = append(page.Legend, legendItem{Name: ws.Warehouse, Color: warehouseColor(i)})
+
+		if svg := threadTimeline(ws.Warehouse, s.results); svg != "" {
+			page.Timelines = append(page.Timelines, timeline{Warehouse: ws.Warehouse, SVG: template.HTML(svg)})
+		}
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": percent,
+	"ms":      ms,
+	"row":     func(qs QueryStats) []string { return queryRow(qs, percent)[2:] },
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Отчет: {{.Source}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 22px; } h2 { font-size: 18px; margin-top: 32px; } h3 { font-size: 15px; }
+table { border-collapse: collapse; font-size: 13px; margin: 8px 0; }
+th, td { border: 1px solid #ddd; padding: 3px 8px; text-align: right; }
+th { background: #f4f4f4; }
+td.name, th.name { text-align: left; }
+.scroll { overflow-x: auto; }
+.chart .grid { stroke: #e5e5e5; }
+.chart .tick { font-size: 10px; fill: #555; }
+.legend span { display: inline-block; margin-right: 16px; font-size: 13px; }
+.legend i { display: inline-block; width: 12px; height: 12px; margin-right: 4px; vertical-align: middle; }
+.muted { color: #777; font-size: 13px; }
+</style>
+</head>
+<body>
+<h1>Отчет: {{.Source}}</h1>
+<p class="muted">сформирован {{.Generated}}</p>
+
+<div class="legend">{{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.Name}}</span>{{end}}</div>
+
+<h2>Хранилища</h2>
+<table>
+<tr><th class="name">хранилище</th><th>запросов</th><th>запусков</th><th>успешно</th><th>ошибок</th><th>пропущено</th><th>успешность</th><th>сумма</th><th>wall</th><th>geomean</th></tr>
+{{range .Warehouses}}<tr><td class="name">{{.Warehouse}}</td><td>{{.Queries}}</td><td>{{.Count}}</td><td>{{.Succeeded}}</td><td>{{.Failed}}</td><td>{{.Skipped}}</td><td>{{percent .SuccessRate}}</td><td>{{ms .TotalMs}}</td><td>{{ms .WallMs}}</td><td>{{if .Succeeded}}{{ms .GeomeanMs}}{{else}}-{{end}}</td></tr>
+{{end}}</table>
+
+<h2>Медиана по запросам, ms</h2>
+{{if .MedianBars}}<div class="scroll">{{.MedianBars}}</div>{{else}}<p class="muted">нет успешных запусков</p>{{end}}
+
+<h2>Распределение длительности</h2>
+<p class="muted">min - p25 - медиана - p75 - max успешных запусков, логарифмическая шкала</p>
+{{if .LatencyBoxes}}<div class="scroll">{{.LatencyBoxes}}</div>{{else}}<p class="muted">нет успешных запусков</p>{{end}}
+
+<h2>Выполнение по потокам</h2>
+<p class="muted">зеленый - успешно, красный - ошибка</p>
+{{range .Timelines}}<h3>{{.Warehouse}}</h3>
+<div class="scroll">{{.SVG}}</div>
+{{else}}<p class="muted">нет времени старта и завершения запросов</p>{{end}}
+
+<h2>Ошибки</h2>
+{{if .Failures.Rows}}<p class="muted">ошибок / запусков; серый - только пропуски</p>
+<table>
+<tr><th class="name">запрос</th>{{range .Failures.Warehouses}}<th>{{.}}</th>{{end}}</tr>
+{{range .Failures.Rows}}<tr><td class="name">{{.QueryID}}</td>{{range .Cells}}<td style="{{.Style}}" title="{{.Title}}">{{.Text}}</td>{{end}}</tr>
+{{end}}</table>
+{{else}}<p class="muted">все запуски успешны</p>{{end}}
+
+<h2>Запросы</h2>
+<table>
+<tr><th class="name">хранилище</th><th class="name">запрос</th><th>запусков</th><th>успешность</th><th>min</th><th>медиана</th><th>mean</th><th>p90</th><th>p95</th><th>p99</th><th>max</th><th>stddev</th><th>cv</th></tr>
+{{range .Queries}}<tr><td class="name">{{.Warehouse}}</td><td class="name">{{.QueryID}}</td>{{range row .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,307 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Matrix - медианы запросов по хранилищам с победителем и ускорением относительно базового хранилища
+type Matrix struct {
+	Source     string
+	Baseline   string
+	Warehouses []string
+	Rows       []MatrixRow
+	Totals     []MatrixTotal
+}
+
+// MatrixRow - запрос; NaN в Medians - нет успешных запусков на хранилище: запрос там
+// не выполнялся или все запуски с ошибкой (Failed). Такое хранилище не побеждает
+// и не участвует в ускорениях и итогах по этому запросу
+type MatrixRow struct {
+	QueryID string
+	Medians []float64
+
+	// запрос выполнялся на хранилище, но ни одного успешного запуска
+	Failed []bool
+
+	// median(baseline)/median(хранилище): больше 1 - быстрее базового
+	Speedups []float64
+
+	// индекс самого быстрого хранилища, -1 если данных нет;
+	// при равных медианах - первое по порядку хранилище
+	Winner int
+}
+
+// MatrixTotal - итог хранилища: Best - запросов, где оно быстрее всех,
+// Wins/Losses - быстрее/медленнее базового
+type MatrixTotal struct {
+	Warehouse string
+	Best      int
+	Wins      int
+	Losses    int
+
+	// среднее геометрическое ускорений по запросам с данными на обоих хранилищах
+	GeomeanSpeedup float64
+}
+
+// BuildMatrix разворачивает отчет в матрицу запрос × хранилище; пустой baseline - первое хранилище
+func BuildMatrix(s Summary, baseline string) (Matrix, error) {
+	m := Matrix{Source: s.Source, Baseline: baseline}
+
+	for _, ws := range s.Warehouses {
+		m.Warehouses = append(m.Warehouses, ws.Warehouse)
+	}
+
+	if len(m.Warehouses) == 0 {
+		return m, fmt.Errorf("нет результатов")
+	}
+
+	if m.Baseline == "" {
+		m.Baseline = m.Warehouses[0]
+	}
+
+	base := slices.Index(m.Warehouses, m.Baseline)
+	if base < 0 {
+		return m, fmt.Errorf("базовое хранилище %s не найдено в результатах (есть: %s)", m.Baseline, strings.Join(m.Warehouses, ", "))
+	}
+
+	logs := make([][]float64, len(m.Warehouses))
+	m.Totals = make([]MatrixTotal, len(m.Warehouses))
+	for i, wh := range m.Warehouses {
+		m.Totals[i].Warehouse = wh
+	}
+
+	for _, id := range queryIDs(s) {
+		row := MatrixRow{
+			QueryID:  id,
+			Medians:  make([]float64, len(m.Warehouses)),
+			Speedups: make([]float64, len(m.Warehouses)),
+			Failed:   make([]bool, len(m.Warehouses)),
+			Winner:   -1,
+		}
+
+		for i, wh := range m.Warehouses {
+			row.Medians[i] = math.NaN()
+			if qs := s.query(wh, id); qs != nil {
+				if qs.Latency != nil {
+					row.Medians[i] = math.Max(qs.Latency.Median, 1)
+				} else {
+					row.Failed[i] = qs.Failed > 0
+				}
+			}
+
+			if !math.IsNaN(row.Medians[i]) && (row.Winner < 0 || row.Medians[i] < row.Medians[row.Winner]) {
+				row.Winner = i
+			}
+		}
+
+		for i := range m.Warehouses {
+			row.Speedups[i] = row.Medians[base] / row.Medians[i]
+			if math.IsNaN(row.Speedups[i]) {
+				continue
+			}
+
+			t := &m.Totals[i]
+			logs[i] = append(logs[i], math.Log(row.Speedups[i]))
+
+			switch {
+			case row.Speedups[i] > 1:
+				t.Wins++
+			case row.Speedups[i] < 1:
+				t.Losses++
+			}
+		}
+
+		if row.Winner >= 0 {
+			m.Totals[row.Winner].Best++
+		}
+
+		m.Rows = append(m.Rows, row)
+	}
+
+	for i := range m.Totals {
+		m.Totals[i].GeomeanSpeedup = math.NaN()
+		if len(logs[i]) > 0 {
+			sum := 0.0
+			for _, l := range logs[i] {
+				sum += l
+			}
+			m.Totals[i].GeomeanSpeedup = math.Exp(sum / float64(len(logs[i])))
+		}
+	}
+
+	return m, nil
+}
+
+var MatrixFormats = []string{"table", "markdown", "csv"}
+
+// WriteMatrix выводит матрицу в одном из MatrixFormats
+func WriteMatrix(w io.Writer, m Matrix, format string) error {
+	switch format {
+	case "table":
+		return writeMatrixTable(w, m)
+	case "markdown":
+		return writeMatrixMarkdown(w, m)
+	case "csv":
+		return writeMatrixCSV(w, m)
+	default:
+		return fmt.Errorf("неизвестный формат матрицы: %s (доступны: %s)", format, strings.Join(MatrixFormats, ", "))
+	}
+}
+
+// matrixCells - ячейки строки: медиана, ускорение для небазовых, * у победителя;
+// failed - только ошибки, "-" - нет запусков
+func (m Matrix) matrixCells(row MatrixRow, winner func(string) string) []string {
+	cells := []string{row.QueryID}
+
+	for i, wh := range m.Warehouses {
+		if math.IsNaN(row.Medians[i]) {
+			cell := "-"
+			if row.Failed[i] {
+				cell = "failed"
+			}
+
+			cells = append(cells, cell)
+			continue
+		}
+
+		cell := ms(row.Medians[i])
+		if wh != m.Baseline && !math.IsNaN(row.Speedups[i]) {
+			cell += " " + speedup(row.Speedups[i])
+		}
+		if i == row.Winner {
+			cell = winner(cell)
+		}
+
+		cells = append(cells, cell)
+	}
+
+	name := "-"
+	if row.Winner >= 0 {
+		name = m.Warehouses[row.Winner]
+	}
+
+	return append(cells, name)
+}
+
+func (m Matrix) totalRows() [][]string {
+	rows := [][]string{{"best"}, {"wins/losses vs " + m.Baseline}, {"geomean speedup"}}
+
+	for _, t := range m.Totals {
+		rows[0] = append(rows[0], strconv.Itoa(t.Best))
+
+		if t.Warehouse == m.Baseline {
+			rows[1] = append(rows[1], "-")
+			rows[2] = append(rows[2], "-")
+			continue
+		}
+
+		rows[1] = append(rows[1], fmt.Sprintf("%d/%d", t.Wins, t.Losses))
+		rows[2] = append(rows[2], speedupOrDash(t.GeomeanSpeedup))
+	}
+
+	for i := range rows {
+		rows[i] = append(rows[i], "")
+	}
+
+	return rows
+}
+
+func (m Matrix) header() []string {
+	header := append([]string{"query_id"}, m.Warehouses...)
+	return append(header, "winner")
+}
+
+func speedup(v float64) string {
+	return fmt.Sprintf("x%.2f", v)
+}
+
+func speedupOrDash(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+
+	return speedup(v)
+}
+
+func writeMatrixTable(w io.Writer, m Matrix) error {
+	fmt.Fprintf(w, "медиана, ms; xN - ускорение относительно %s; * - самое быстрое; failed - только ошибки\n\n", m.Baseline)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	writeTabRow(tw, m.header())
+	for _, row := range m.Rows {
+		writeTabRow(tw, m.matrixCells(row, func(cell string) string { return "*" + cell }))
+	}
+
+	fmt.Fprintln(tw)
+
+	for _, row := range m.totalRows() {
+		writeTabRow(tw, row)
+	}
+
+	return tw.Flush()
+}
+
+func writeMatrixMarkdown(w io.Writer, m Matrix) error {
+	fmt.Fprintf(w, "# Матрица: %s\n\n", m.Source)
+	fmt.Fprintf(w, "Медиана, ms; xN - ускорение относительно **%s**; жирным - самое быстрое хранилище; failed - только ошибки.\n\n", m.Baseline)
+
+	writeMarkdownTable(w, m.header(), len(m.Rows), func(i int) []string {
+		return m.matrixCells(m.Rows[i], func(cell string) string { return "**" + cell + "**" })
+	})
+
+	fmt.Fprintf(w, "\n## Итоги\n\n")
+
+	totals := m.totalRows()
+	writeMarkdownTable(w, append([]string{""}, m.Warehouses...), len(totals), func(i int) []string {
+		return totals[i][:len(totals[i])-1]
+	})
+
+	return nil
+}
+
+// writeMatrixCSV - широкая таблица: медиана и ускорение по каждому хранилищу, итоги не пишутся
+func writeMatrixCSV(w io.Writer, m Matrix) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"query_id"}
+	for _, wh := range m.Warehouses {
+		header = append(header, wh+"_median_ms", wh+"_speedup")
+	}
+	cw.Write(append(header, "winner"))
+
+	for _, row := range m.Rows {
+		record := []string{row.QueryID}
+
+		for i := range m.Warehouses {
+			if math.IsNaN(row.Medians[i]) {
+				record = append(record, "", "")
+				continue
+			}
+
+			value := ""
+			if !math.IsNaN(row.Speedups[i]) {
+				value = strconv.FormatFloat(row.Speedups[i], 'f', 4, 64)
+			}
+
+			record = append(record, ms(row.Medians[i]), value)
+		}
+
+		winner := ""
+		if row.Winner >= 0 {
+			winner = m.Warehouses[row.Winner]
+		}
+
+		cw.Write(append(record, winner))
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"tpcds_benchmark/pkg/storage"
+)
+
+func matrixSummary() Summary {
+	ok := storage.StatusSuccess
+
+	return Summarize("run", []storage.BenchmarkResult{
+		run("base", "q1", ok, 0, 100), run("x", "q1", ok, 0, 50), run("y", "q1", ok, 0, 200),
+
+		// все равны: побеждает первое хранилище, ускорения 1 - ни выигрыш, ни проигрыш
+		run("base", "q2", ok, 0, 100), run("x", "q2", ok, 0, 100), run("y", "q2", ok, 0, 100),
+
+		// на x только ошибки
+		run("base", "q3", ok, 0, 100), run("x", "q3", storage.StatusError, 0, 10), run("y", "q3", ok, 0, 25),
+
+		// на базовом не выполнялся: победитель есть, ускорений нет
+		run("x", "q4", ok, 0, 10), run("y", "q4", ok, 0, 20),
+
+		// равенство небазовых: побеждает x как более раннее
+		run("base", "q5", ok, 0, 300), run("x", "q5", ok, 0, 100), run("y", "q5", ok, 0, 100),
+	})
+}
+
+func TestBuildMatrix(t *testing.T) {
+	m, err := BuildMatrix(matrixSummary(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Baseline != "base" {
+		t.Errorf("default baseline = %s, want first warehouse", m.Baseline)
+	}
+
+	nan := math.NaN()
+	want := []struct {
+		winner   int
+		medians  []float64
+		speedups []float64
+		failed   []bool
+	}{
+		{1, []float64{100, 50, 200}, []float64{1, 2, 0.5}, []bool{false, false, false}},
+		{0, []float64{100, 100, 100}, []float64{1, 1, 1}, []bool{false, false, false}},
+		{2, []float64{100, nan, 25}, []float64{1, nan, 4}, []bool{false, true, false}},
+		{1, []float64{nan, 10, 20}, []float64{nan, nan, nan}, []bool{false, false, false}},
+		{1, []float64{300, 100, 100}, []float64{1, 3, 3}, []bool{false, false, false}},
+	}
+
+	if len(m.Rows) != len(want) {
+		t.Fatalf("rows: %d, want %d", len(m.Rows), len(want))
+	}
+
+	for i, w := range want {
+		row := m.Rows[i]
+
+		if row.Winner != w.winner {
+			t.Errorf("%s: winner %d, want %d", row.QueryID, row.Winner, w.winner)
+		}
+
+		for j := range m.Warehouses {
+			if !near(row.Medians[j], w.medians[j]) || !near(row.Speedups[j], w.speedups[j]) || row.Failed[j] != w.failed[j] {
+				t.Errorf("%s/%s: median %v speedup %v failed %v, want %v %v %v", row.QueryID, m.Warehouses[j],
+					row.Medians[j], row.Speedups[j], row.Failed[j], w.medians[j], w.speedups[j], w.failed[j])
+			}
+		}
+	}
+
+	totals := []MatrixTotal{
+		{Warehouse: "base", Best: 1, GeomeanSpeedup: 1},
+		{Warehouse: "x", Best: 3, Wins: 2, GeomeanSpeedup: math.Cbrt(2 * 1 * 3)},
+		{Warehouse: "y", Best: 1, Wins: 2, Losses: 1, GeomeanSpeedup: math.Pow(0.5*1*4*3, 0.25)},
+	}
+
+	for i, w := range totals {
+		got := m.Totals[i]
+		if got.Warehouse != w.Warehouse || got.Best != w.Best || got.Wins != w.Wins || got.Losses != w.Losses || !near(got.GeomeanSpeedup, w.GeomeanSpeedup) {
+			t.Errorf("totals %s: %+v, want %+v", w.Warehouse, got, w)
+		}
+	}
+}
+
+func TestBuildMatrixBaseline(t *testing.T) {
+	m, err := BuildMatrix(matrixSummary(), "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// q4 теперь с ускорением: 20 / 10
+	if got := m.Rows[3].Speedups[1]; !near(got, 2) {
+		t.Errorf("q4 x speedup vs y = %v, want 2", got)
+	}
+
+	if _, err := BuildMatrix(matrixSummary(), "nope"); err == nil {
+		t.Error("unknown baseline accepted")
+	}
+
+	if _, err := BuildMatrix(Summarize("empty", nil), ""); err == nil {
+		t.Error("empty summary accepted")
+	}
+}
+
+func TestWriteMatrixMarksMissingAndFailedCells(t *testing.T) {
+	m, err := BuildMatrix(matrixSummary(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMatrix(&buf, m, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	find := func(prefix string) string {
+		for _, line := range lines {
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		}
+		t.Fatalf("no line %q in:\n%s", prefix, buf.String())
+		return ""
+	}
+
+	if q3 := find("| q3 |"); !strings.Contains(q3, "| failed |") || !strings.Contains(q3, "**25 x4.00**") {
+		t.Errorf("q3 row: %s", q3)
+	}
+
+	if q4 := find("| q4 |"); !strings.HasPrefix(q4, "| q4 | - |") {
+		t.Errorf("q4 row: %s", q4)
+	}
+
+	buf.Reset()
+	if err := WriteMatrix(&buf, m, "csv"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "q4,,,10,,20,,x\n") {
+		t.Errorf("csv without speedups for missing baseline:\n%s", buf.String())
+	}
+}
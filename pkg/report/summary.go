@@ -0,0 +1,221 @@
+package report
+
+import (
+	"time"
+	"tpcds_benchmark/pkg/stats"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// Summary - агрегированный отчет по результатам запуска
+type Summary struct {
+	// файл результатов или запуск из истории
+	Source string `json:"source"`
+
+	Warehouses []WarehouseStats `json:"warehouses"`
+	Queries    []QueryStats     `json:"queries"`
+
+	// исходные результаты для графиков html
+	results []storage.BenchmarkResult
+}
+
+// QueryStats - статистика запроса на хранилище
+type QueryStats struct {
+	Warehouse string `json:"warehouse"`
+	QueryID   string `json:"query_id"`
+
+	Count     int `json:"count"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+
+	// not_run и skipped_circuit_open
+	Skipped int `json:"skipped"`
+
+	// доля успешных среди выполненных (без пропущенных)
+	SuccessRate float64 `json:"success_rate"`
+
+	// nil, если нет ни одного успешного запуска
+	Latency *Latency `json:"latency_ms,omitempty"`
+}
+
+// Latency - распределение длительности успешных запусков, ms
+type Latency struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Mean   float64 `json:"mean"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+
+	// коэффициент вариации stddev/mean
+	CV float64 `json:"cv"`
+}
+
+// WarehouseStats - итог хранилища по всем запросам
+type WarehouseStats struct {
+	Warehouse string `json:"warehouse"`
+	Queries   int    `json:"queries"`
+
+	Count       int     `json:"count"`
+	Succeeded   int     `json:"succeeded"`
+	Failed      int     `json:"failed"`
+	Skipped     int     `json:"skipped"`
+	SuccessRate float64 `json:"success_rate"`
+
+	// сумма длительностей успешных запусков и время от первого старта до последнего завершения
+	TotalMs float64 `json:"total_ms"`
+	WallMs  float64 `json:"wall_ms"`
+
+	// среднее геометрическое медиан запросов с успешными запусками
+	GeomeanMs float64 `json:"geomean_ms"`
+}
+
+type queryKey struct {
+	warehouse string
+	queryID   string
+}
+
+// Summarize считает статистику по запросам и хранилищам; порядок - порядок появления в результатах
+func Summarize(source string, results []storage.BenchmarkResult) Summary {
+	var (
+		warehouses []string
+		keys       []queryKey
+		byQuery    = make(map[queryKey][]storage.BenchmarkResult)
+		byWh       = make(map[string][]storage.BenchmarkResult)
+	)
+
+	for _, r := range results {
+		key := queryKey{r.Warehouse, r.QueryID}
+
+		if _, ok := byWh[r.Warehouse]; !ok {
+			warehouses = append(warehouses, r.Warehouse)
+		}
+		if _, ok := byQuery[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		byWh[r.Warehouse] = append(byWh[r.Warehouse], r)
+		byQuery[key] = append(byQuery[key], r)
+	}
+
+	summary := Summary{Source: source, results: results}
+
+	medians := make(map[string][]float64)
+
+	for _, key := range keys {
+		qs := summarizeQuery(key, byQuery[key])
+		summary.Queries = append(summary.Queries, qs)
+
+		if qs.Latency != nil {
+			medians[key.warehouse] = append(medians[key.warehouse], qs.Latency.Median)
+		}
+	}
+
+	for _, wh := range warehouses {
+		ws := WarehouseStats{Warehouse: wh}
+
+		var first, last time.Time
+
+		for _, qs := range summary.Queries {
+			if qs.Warehouse == wh {
+				ws.Queries++
+			}
+		}
+
+		for _, r := range byWh[wh] {
+			ws.Count++
+
+			switch r.Status {
+			case storage.StatusSuccess:
+				ws.Succeeded++
+				ws.TotalMs += float64(r.DurationMs)
+			case storage.StatusError:
+				ws.Failed++
+			default:
+				ws.Skipped++
+			}
+
+			if !r.StartTimestamp.IsZero() && (first.IsZero() || r.StartTimestamp.Before(first)) {
+				first = r.StartTimestamp
+			}
+			if r.EndTimestamp.After(last) {
+				last = r.EndTimestamp
+			}
+		}
+
+		ws.SuccessRate = successRate(ws.Succeeded, ws.Failed)
+
+		if !first.IsZero() && last.After(first) {
+			ws.WallMs = float64(last.Sub(first).Milliseconds())
+		}
+
+		if len(medians[wh]) > 0 {
+			ws.GeomeanMs = stats.Geomean(medians[wh])
+		}
+
+		summary.Warehouses = append(summary.Warehouses, ws)
+	}
+
+	return summary
+}
+
+func summarizeQuery(key queryKey, results []storage.BenchmarkResult) QueryStats {
+	qs := QueryStats{
+		Warehouse: key.warehouse,
+		QueryID:   key.queryID,
+		Count:     len(results),
+	}
+
+	var durations []float64
+
+	for _, r := range results {
+		switch r.Status {
+		case storage.StatusSuccess:
+			qs.Succeeded++
+			durations = append(durations, float64(r.DurationMs))
+		case storage.StatusError:
+			qs.Failed++
+		default:
+			qs.Skipped++
+		}
+	}
+
+	qs.SuccessRate = successRate(qs.Succeeded, qs.Failed)
+	qs.Latency = newLatency(durations)
+
+	return qs
+}
+
+func newLatency(durations []float64) *Latency {
+	if len(durations) == 0 {
+		return nil
+	}
+
+	sorted := stats.Sorted(durations)
+
+	l := &Latency{
+		Min:    sorted[0],
+		Median: stats.Percentile(sorted, 50),
+		Mean:   stats.Mean(sorted),
+		P90:    stats.Percentile(sorted, 90),
+		P95:    stats.Percentile(sorted, 95),
+		P99:    stats.Percentile(sorted, 99),
+		Max:    sorted[len(sorted)-1],
+		StdDev: stats.StdDev(sorted),
+	}
+
+	if l.Mean > 0 {
+		l.CV = l.StdDev / l.Mean
+	}
+
+	return l
+}
+
+func successRate(succeeded, failed int) float64 {
+	if succeeded+failed == 0 {
+		return 0
+	}
+
+	return float64(succeeded) / float64(succeeded+failed)
+}
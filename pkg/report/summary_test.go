@@ -0,0 +1,122 @@
+package report
+
+import (
+	"math"
+	"testing"
+	"time"
+	"tpcds_benchmark/pkg/storage"
+)
+
+var t0 = time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+// run - результат запроса; start и duration в ms от t0, у пропущенных времени нет
+func run(warehouse, queryID, status string, start, duration int) storage.BenchmarkResult {
+	r := storage.BenchmarkResult{
+		Warehouse:  warehouse,
+		QueryID:    queryID,
+		Status:     status,
+		DurationMs: duration,
+	}
+
+	if status == storage.StatusSuccess || status == storage.StatusError {
+		r.StartTimestamp = t0.Add(time.Duration(start) * time.Millisecond)
+		r.EndTimestamp = r.StartTimestamp.Add(time.Duration(duration) * time.Millisecond)
+	}
+
+	return r
+}
+
+func near(got, want float64) bool {
+	if math.IsNaN(want) {
+		return math.IsNaN(got)
+	}
+
+	return math.Abs(got-want) < 1e-9
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize("run", []storage.BenchmarkResult{
+		run("b", "q1", storage.StatusSuccess, 0, 50),
+		run("a", "q1", storage.StatusSuccess, 0, 100),
+		run("a", "q1", storage.StatusSuccess, 100, 200),
+		run("a", "q1", storage.StatusError, 300, 1200),
+		run("a", "q2", storage.StatusNotRun, 0, 0),
+		run("a", "q2", storage.StatusSkippedCircuitOpen, 0, 0),
+	})
+
+	if len(s.Warehouses) != 2 || s.Warehouses[0].Warehouse != "b" || s.Warehouses[1].Warehouse != "a" {
+		t.Fatalf("warehouses not in order of appearance: %+v", s.Warehouses)
+	}
+
+	q := s.query("a", "q1")
+	if q == nil {
+		t.Fatal("a/q1 missing")
+	}
+
+	if q.Count != 3 || q.Succeeded != 2 || q.Failed != 1 || q.Skipped != 0 || !near(q.SuccessRate, 2.0/3) {
+		t.Errorf("a/q1 counts: %+v", q)
+	}
+
+	// 100 и 200: медиана и среднее 150, stddev = sqrt((50² + 50²) / 1)
+	l := q.Latency
+	want := Latency{Min: 100, Median: 150, Mean: 150, P90: 190, P95: 195, P99: 199, Max: 200, StdDev: math.Sqrt(5000), CV: math.Sqrt(5000) / 150}
+	if l == nil {
+		t.Fatal("a/q1 latency missing")
+	}
+
+	for name, pair := range map[string][2]float64{
+		"min": {l.Min, want.Min}, "median": {l.Median, want.Median}, "mean": {l.Mean, want.Mean},
+		"p90": {l.P90, want.P90}, "p95": {l.P95, want.P95}, "p99": {l.P99, want.P99},
+		"max": {l.Max, want.Max}, "stddev": {l.StdDev, want.StdDev}, "cv": {l.CV, want.CV},
+	} {
+		if !near(pair[0], pair[1]) {
+			t.Errorf("a/q1 %s = %v, want %v", name, pair[0], pair[1])
+		}
+	}
+
+	// только пропуски: успешность 0 без деления на ноль, распределения нет
+	skipped := s.query("a", "q2")
+	if skipped == nil || skipped.Skipped != 2 || skipped.SuccessRate != 0 || skipped.Latency != nil {
+		t.Errorf("a/q2: %+v", skipped)
+	}
+
+	single := s.query("b", "q1")
+	if single == nil || single.Latency == nil || single.Latency.StdDev != 0 || single.Latency.CV != 0 {
+		t.Errorf("b/q1 with one run: %+v", single)
+	}
+
+	a := s.Warehouses[1]
+	if a.Queries != 2 || a.Count != 5 || a.Succeeded != 2 || a.Failed != 1 || a.Skipped != 2 {
+		t.Errorf("warehouse a counts: %+v", a)
+	}
+
+	// сумма только успешных; wall - от первого старта до конца запроса с ошибкой (300 + 1200)
+	if a.TotalMs != 300 || a.WallMs != 1500 || !near(a.SuccessRate, 2.0/3) {
+		t.Errorf("warehouse a totals: %+v", a)
+	}
+
+	// медиана есть только у q1
+	if !near(a.GeomeanMs, 150) {
+		t.Errorf("warehouse a geomean = %v, want 150", a.GeomeanMs)
+	}
+}
+
+func TestSummarizeGeomeanClampsZeroMedians(t *testing.T) {
+	s := Summarize("run", []storage.BenchmarkResult{
+		run("a", "q1", storage.StatusSuccess, 0, 0),
+		run("a", "q2", storage.StatusSuccess, 0, 100),
+	})
+
+	// медиана 0 считается за 1 ms: sqrt(1 * 100)
+	if got := s.Warehouses[0].GeomeanMs; !near(got, 10) {
+		t.Errorf("geomean = %v, want 10", got)
+	}
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	s := Summarize("empty", nil)
+
+	if len(s.Warehouses) != 0 || len(s.Queries) != 0 {
+		t.Errorf("expected empty summary, got %+v", s)
+	}
+}
@@ -0,0 +1,343 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"tpcds_benchmark/pkg/stats"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// цвета хранилищ в графиках, по кругу
+var palette = []string{"#4e79a7", "#f28e2b", "#59a14f", "#e15759", "#76b7b2", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac"}
+
+const (
+	colorSuccess = "#59a14f"
+	colorError   = "#e15759"
+	colorSkipped = "#bab0ac"
+)
+
+func warehouseColor(i int) string {
+	return palette[i%len(palette)]
+}
+
+func queryIDs(s Summary) []string {
+	var ids []string
+	seen := make(map[string]bool)
+
+	for _, qs := range s.Queries {
+		if !seen[qs.QueryID] {
+			seen[qs.QueryID] = true
+			ids = append(ids, qs.QueryID)
+		}
+	}
+
+	return ids
+}
+
+func (s Summary) query(warehouse, queryID string) *QueryStats {
+	for i := range s.Queries {
+		if s.Queries[i].Warehouse == warehouse && s.Queries[i].QueryID == queryID {
+			return &s.Queries[i]
+		}
+	}
+
+	return nil
+}
+
+// niceStep - шаг делений оси: 1, 2 или 5 * 10^n, не меньше max/ticks
+func niceStep(max float64, ticks int) float64 {
+	if max <= 0 {
+		return 1
+	}
+
+	raw := max / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+
+	for _, m := range []float64{1, 2, 5, 10} {
+		if step := m * magnitude; step >= raw {
+			return step
+		}
+	}
+
+	return 10 * magnitude
+}
+
+// medianBars - медиана каждого запроса, столбцы хранилищ сгруппированы по запросу
+func medianBars(s Summary) string {
+	ids := queryIDs(s)
+	if len(ids) == 0 {
+		return ""
+	}
+
+	const (
+		barWidth = 10
+		gap      = 12
+		height   = 260
+		top      = 20
+		left     = 60
+		bottom   = 70
+	)
+
+	group := len(s.Warehouses)*barWidth + gap
+	width := left + len(ids)*group + 20
+
+	max := 0.0
+	for _, qs := range s.Queries {
+		if qs.Latency != nil {
+			max = math.Max(max, qs.Latency.Median)
+		}
+	}
+
+	step := niceStep(max, 5)
+	axisMax := math.Ceil(max/step) * step
+	if axisMax == 0 {
+		axisMax = step
+	}
+
+	y := func(v float64) float64 {
+		return top + height - v/axisMax*height
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" class="chart">`, width, top+height+bottom)
+
+	for v := 0.0; v <= axisMax+step/2; v += step {
+		fmt.Fprintf(&b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="grid"/>`, left, width-20, y(v), y(v))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, left-6, y(v)+4, formatMs(v))
+	}
+
+	for qi, id := range ids {
+		x0 := left + qi*group + gap/2
+
+		for wi, ws := range s.Warehouses {
+			qs := s.query(ws.Warehouse, id)
+			if qs == nil || qs.Latency == nil {
+				continue
+			}
+
+			v := qs.Latency.Median
+			fmt.Fprintf(&b, `<rect x="%d" y="%.1f" width="%d" height="%.1f" fill="%s"><title>%s / %s: медиана %s, p95 %s</title></rect>`,
+				x0+wi*barWidth,
+				y(v),
+				barWidth-1,
+				top+height-y(v),
+				warehouseColor(wi),
+				html.EscapeString(ws.Warehouse),
+				html.EscapeString(id),
+				formatMs(v),
+				formatMs(qs.Latency.P95),
+			)
+		}
+
+		cx := x0 + len(s.Warehouses)*barWidth/2
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="tick" text-anchor="end" transform="rotate(-60 %d %d)">%s</text>`,
+			cx, top+height+12, cx, top+height+12, html.EscapeString(id))
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// latencyBoxes - распределение длительности успешных запусков (min, p25, медиана, p75, max)
+// по запросам и хранилищам на логарифмической оси
+func latencyBoxes(s Summary) string {
+	ids := queryIDs(s)
+
+	durations := make(map[queryKey][]float64)
+	max := 1.0
+
+	for _, r := range s.results {
+		if r.Status != storage.StatusSuccess {
+			continue
+		}
+
+		key := queryKey{r.Warehouse, r.QueryID}
+		durations[key] = append(durations[key], float64(r.DurationMs))
+		max = math.Max(max, float64(r.DurationMs))
+	}
+
+	if len(durations) == 0 {
+		return ""
+	}
+
+	const (
+		row    = 10
+		gap    = 8
+		top    = 10
+		left   = 90
+		width  = 900
+		bottom = 30
+	)
+
+	plot := float64(width - left - 20)
+	decades := math.Ceil(math.Log10(max))
+	if decades < 1 {
+		decades = 1
+	}
+
+	x := func(v float64) float64 {
+		return float64(left) + math.Log10(math.Max(v, 1))/decades*plot
+	}
+
+	group := len(s.Warehouses)*row + gap
+	height := top + len(ids)*group + bottom
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" class="chart">`, width, height)
+
+	for d := 0.0; d <= decades; d++ {
+		v := math.Pow(10, d)
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%d" y2="%d" class="grid"/>`, x(v), x(v), top, height-bottom)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, x(v), height-bottom+14, formatMs(v))
+	}
+
+	for qi, id := range ids {
+		y0 := top + qi*group
+
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="tick" text-anchor="end">%s</text>`,
+			left-6, y0+len(s.Warehouses)*row/2+4, html.EscapeString(id))
+
+		for wi, ws := range s.Warehouses {
+			values := durations[queryKey{ws.Warehouse, id}]
+			if len(values) == 0 {
+				continue
+			}
+
+			sorted := stats.Sorted(values)
+			min, p25, median, p75, hi := sorted[0], stats.Percentile(sorted, 25), stats.Percentile(sorted, 50), stats.Percentile(sorted, 75), sorted[len(sorted)-1]
+
+			cy := float64(y0+wi*row) + row/2.0
+			color := warehouseColor(wi)
+
+			fmt.Fprintf(&b, `<g><title>%s / %s: n=%d, min %s, p25 %s, медиана %s, p75 %s, max %s</title>`,
+				html.EscapeString(ws.Warehouse), html.EscapeString(id), len(sorted),
+				formatMs(min), formatMs(p25), formatMs(median), formatMs(p75), formatMs(hi))
+			fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="%s"/>`, x(min), x(hi), cy, cy, color)
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s" fill-opacity="0.5" stroke="%s"/>`,
+				x(p25), cy-row/2.0+1, math.Max(x(p75)-x(p25), 1), row-2, color, color)
+			fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="#000" stroke-width="1.5"/>`, x(median), x(median), cy-row/2.0+1, cy+row/2.0-1)
+			b.WriteString(`</g>`)
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// threadTimeline - диаграмма Ганта выполнения запросов по потокам хранилища
+func threadTimeline(warehouse string, results []storage.BenchmarkResult) string {
+	var (
+		first, last time.Time
+		threads     []int
+		seen        = make(map[int]bool)
+		runs        []storage.BenchmarkResult
+	)
+
+	for _, r := range results {
+		if r.Warehouse != warehouse || r.StartTimestamp.IsZero() || r.EndTimestamp.IsZero() {
+			continue
+		}
+
+		if first.IsZero() || r.StartTimestamp.Before(first) {
+			first = r.StartTimestamp
+		}
+		if r.EndTimestamp.After(last) {
+			last = r.EndTimestamp
+		}
+
+		if !seen[r.ThreadID] {
+			seen[r.ThreadID] = true
+			threads = append(threads, r.ThreadID)
+		}
+
+		runs = append(runs, r)
+	}
+
+	if len(runs) == 0 || !last.After(first) {
+		return ""
+	}
+
+	sort.Ints(threads)
+
+	row := make(map[int]int, len(threads))
+	for i, t := range threads {
+		row[t] = i
+	}
+
+	const (
+		rowHeight = 14
+		top       = 10
+		left      = 70
+		width     = 1100
+		bottom    = 30
+	)
+
+	total := last.Sub(first).Seconds()
+	plot := float64(width - left - 20)
+
+	x := func(t time.Time) float64 {
+		return float64(left) + t.Sub(first).Seconds()/total*plot
+	}
+
+	height := top + len(threads)*rowHeight + bottom
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" class="chart">`, width, height)
+
+	step := niceStep(total, 10)
+	for v := 0.0; v <= total; v += step {
+		px := float64(left) + v/total*plot
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%d" y2="%d" class="grid"/>`, px, px, top, height-bottom)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, px, height-bottom+14, formatSeconds(v))
+	}
+
+	for i, t := range threads {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="tick" text-anchor="end">поток %d</text>`, left-6, top+i*rowHeight+rowHeight-3, t)
+	}
+
+	for _, r := range runs {
+		color := colorSuccess
+		if r.Status == storage.StatusError {
+			color = colorError
+		}
+
+		fmt.Fprintf(&b, `<rect x="%.2f" y="%d" width="%.2f" height="%d" fill="%s" stroke="#fff" stroke-width="0.3"><title>%s запуск %d: %s, %s</title></rect>`,
+			x(r.StartTimestamp),
+			top+row[r.ThreadID]*rowHeight+1,
+			math.Max(x(r.EndTimestamp)-x(r.StartTimestamp), 0.5),
+			rowHeight-2,
+			color,
+			html.EscapeString(r.QueryID),
+			r.RunNumber,
+			r.Status,
+			formatMs(float64(r.DurationMs)),
+		)
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+func formatMs(v float64) string {
+	switch {
+	case v >= 60000:
+		return fmt.Sprintf("%.1fm", v/60000)
+	case v >= 1000:
+		return fmt.Sprintf("%.1fs", v/1000)
+	default:
+		return fmt.Sprintf("%.0fms", v)
+	}
+}
+
+func formatSeconds(v float64) string {
+	seconds := int(math.Round(v))
+	if seconds >= 60 {
+		return fmt.Sprintf("%dm%02ds", seconds/60, seconds%60)
+	}
+
+	return fmt.Sprintf("%ds", seconds)
+}
@@ -2,13 +2,20 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 	"tpcds_benchmark/pkg/connection"
 	"tpcds_benchmark/pkg/executor"
+	"tpcds_benchmark/pkg/history"
+	"tpcds_benchmark/pkg/manifest"
 	"tpcds_benchmark/pkg/query"
+	"tpcds_benchmark/pkg/report"
 	"tpcds_benchmark/pkg/storage"
 
 	"tpcds_benchmark/pkg/config"
@@ -17,26 +24,78 @@ import (
 type BenchmarkRunner struct {
 	cfg     *config.Config
 	connMgr *connection.ConnectionManager
-	storage *storage.CSVStorage
+	sink    storage.ResultSink
+	history *history.Sink
 	queries []query.Query
 	timeout time.Duration
 	s3      *storage.S3Storage
+
+	// путь к результатам запуска без расширения, для дополнительных файлов
+	resultsBase string
+
+	// окружение, конфигурация и версии движков, пишется в resultsBase_manifest.json
+	manifest *manifest.Manifest
+
+	// задачи, уже выполненные в продолжаемом файле результатов
+	completed map[taskKey]bool
+
+	// общий бюджет времени запуска, ctx отменяется на его жестком дедлайне
+	ctx      context.Context
+	budget   budget
+	grace    time.Duration
+	coverage coverage
+
+	// step-load: throughput и перцентили по шагам concurrency
+	curve stepCurve
+
+	// классы запросов сценария смешанной нагрузки
+	classes []queryClass
+
+	// все результаты запуска (с продолжаемым файлом) для итогового отчета
+	resultsMu sync.Mutex
+	results   []storage.BenchmarkResult
 }
 
-func NewBenchmarkRunner(cfg *config.Config, connMgr *connection.ConnectionManager, s3 *storage.S3Storage, filename string) (*BenchmarkRunner, error) {
+// resumePath - существующий файл результатов, в который дописываются недостающие задачи
+func NewBenchmarkRunner(cfg *config.Config, connMgr *connection.ConnectionManager, s3 *storage.S3Storage, filename, resumePath string) (*BenchmarkRunner, error) {
+
+	var (
+		completed map[taskKey]bool
+		previous  []storage.BenchmarkResult
+		err       error
+	)
+
+	if resumePath != "" && (cfg.OpenLoop != nil && cfg.OpenLoop.Enabled ||
+		cfg.StepLoad != nil && cfg.StepLoad.Enabled ||
+		cfg.Scenario != nil && cfg.Scenario.Enabled) {
+		return nil, fmt.Errorf("продолжение запуска не поддерживается для open_loop, step_load и scenario")
+	}
+
+	resultsBase := filepath.Join(cfg.ResultsPath, strings.TrimSuffix(filename, filepath.Ext(filename)))
+
+	if resumePath != "" {
+		resultsBase = strings.TrimSuffix(resumePath, filepath.Ext(resumePath))
+
+		completed, previous, err = loadCompleted(resumePath)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания хранилища: %w", err)
+		}
+	}
 
-	st, err := storage.NewCSVStorage(cfg.ResultsPath, filename)
+	st, hist, err := createSinks(cfg, connMgr, filename, resumePath)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания хранилища: %w", err)
 	}
 
-	loader := query.NewQueryLoader(cfg.QueriesPath)
-	queries, err := loader.LoadAll()
+	queries, err := suiteLoader(cfg.QuerySuite()).LoadAll()
 	if err != nil {
 		st.Close()
 		return nil, fmt.Errorf("ошибка загрузки запросов: %w", err)
 	}
 
+	if cfg.Suite != "" {
+		log.Printf("набор запросов: %s", cfg.Suite)
+	}
 	log.Printf("загружено %d запросов", len(queries))
 
 	timeout, err := time.ParseDuration(cfg.Timeout)
@@ -45,19 +104,50 @@ func NewBenchmarkRunner(cfg *config.Config, connMgr *connection.ConnectionManage
 		return nil, fmt.Errorf("неверный таймаут: %w", err)
 	}
 
+	var classes []queryClass
+	if cfg.Scenario != nil && cfg.Scenario.Enabled {
+		classes, err = loadClasses(cfg, timeout)
+		if err != nil {
+			st.Close()
+			return nil, err
+		}
+	}
+
+	m, err := manifest.New(filepath.Base(resultsBase), cfg, queries)
+	if err != nil {
+		st.Close()
+		return nil, err
+	}
+
+	if resumePath != "" {
+		if err := m.Resume(manifestPath(resultsBase)); err != nil {
+			log.Printf("WARNING: %v, манифест начнется заново", err)
+		}
+	}
+
 	return &BenchmarkRunner{
 		cfg:     cfg,
 		connMgr: connMgr,
-		storage: st,
+		sink:    st,
+		history: hist,
 		queries: queries,
 		timeout: timeout,
 		s3:      s3,
+
+		resultsBase: resultsBase,
+		manifest:    m,
+
+		completed: completed,
+		classes:   classes,
+		results:   previous,
+		ctx:       context.Background(),
+		grace:     parseOptionalDuration(cfg.DeadlineGrace),
 	}, nil
 
 }
 
 func (br *BenchmarkRunner) Run() error {
-	defer br.storage.Close()
+	defer br.sink.Close()
 
 	activeWarehouses := 0
 
@@ -78,204 +168,457 @@ func (br *BenchmarkRunner) Run() error {
 	log.Printf("параллельность: %d потоков", br.cfg.Concurrency)
 	log.Printf("активных хранилищ: %d", activeWarehouses)
 
+	if err := br.manifest.Write(br.manifestPath()); err != nil {
+		log.Printf("%v", err)
+	}
+
+	br.budget = newBudget(time.Now(), parseOptionalDuration(br.cfg.MaxTotalDuration), br.grace)
+	if !br.budget.soft.IsZero() {
+		log.Printf("бюджет времени: до %s, жесткий дедлайн %s",
+			br.budget.soft.Format(time.TimeOnly),
+			br.budget.hard.Format(time.TimeOnly),
+		)
+	}
+
+	ctx, cancel := br.budget.context(context.Background())
+	defer cancel()
+	br.ctx = ctx
+
+	var warehouses []config.WarehouseConfig
 	for _, wh := range br.cfg.Warehouses {
 		if !wh.Enabled {
 			log.Printf("пропуск неактивного хранилища: %s", wh.Name)
 			continue
 		}
 
-		if err := br.runWarehouse(wh); err != nil {
-			log.Printf("ERROR: %v хранилище %s", err, wh.Name)
-		}
+		warehouses = append(warehouses, wh)
+	}
 
+	switch {
+	case br.cfg.ExecutionOrder == config.OrderInterleaved || br.cfg.ExecutionOrder == config.OrderRandom:
+		br.runInterleaved(warehouses)
+	case br.cfg.ParallelWarehouses:
+		br.runParallel(warehouses)
+	default:
+		br.runSequential(warehouses)
 	}
 
-	if err := br.storage.Close(); err != nil {
-		return fmt.Errorf("ошибка при закрытии файла: %w", err)
+	br.coverage.log()
+
+	if err := br.sink.Close(); err != nil {
+		err = fmt.Errorf("ошибка при закрытии файла: %w", err)
+		br.finishManifest(manifest.StatusFailed, err)
+		return err
 	}
-	log.Printf("тест завершен, результаты записаны в: %s", br.cfg.ResultsPath)
 
-	filePath := br.storage.GetFilePath()
+	artifacts := br.sink.Artifacts()
 
-	if br.s3 != nil {
-		if err := br.s3.Upload(filePath); err != nil {
-			log.Printf("ошибка при загрузке файла в s3: %v", err)
-			return nil
+	files := make([]string, len(artifacts))
+	for i, a := range artifacts {
+		files[i] = a.Path
+	}
+
+	log.Printf("тест завершен, результаты записаны в: %s", strings.Join(files, ", "))
+
+	artifacts = append(artifacts, br.writeReport()...)
+
+	if br.cfg.StepLoad != nil && br.cfg.StepLoad.Enabled {
+		br.curve.log()
+
+		curvePath := br.resultsBase + "_steps.csv"
+		if err := br.curve.write(curvePath); err != nil {
+			log.Printf("ошибка записи кривой нагрузки: %v", err)
+		} else {
+			log.Printf("кривая нагрузки записана в: %s", curvePath)
+			artifacts = append(artifacts, storage.FileArtifact(curvePath))
 		}
+	}
+
+	artifacts = append(artifacts, br.finishManifest(manifest.StatusCompleted, nil)...)
 
-		log.Printf("файл успешно загружен в s3")
+	if br.s3 != nil {
+		uploadArtifacts(br.s3, artifacts)
 	}
 	return nil
 }
 
+// artifactUploader - s3, куда загружаются файлы запуска
+type artifactUploader interface {
+	UploadAs(filePath, key string) error
+}
+
+// uploadArtifacts загружает все файлы запуска; ошибка одного файла не мешает остальным.
+// Возвращает число незагруженных файлов
+func uploadArtifacts(s3 artifactUploader, artifacts []storage.Artifact) int {
+	failed := 0
+
+	for _, a := range artifacts {
+		if err := s3.UploadAs(a.Path, a.Key); err != nil {
+			log.Printf("ошибка при загрузке файла %s в s3: %v", a.Path, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		log.Printf("WARNING: не загружено в s3 %d из %d файлов", failed, len(artifacts))
+	} else {
+		log.Printf("файлы успешно загружены в s3: %d", len(artifacts))
+	}
+
+	return failed
+}
+
+// writeReport печатает сводный отчет и сохраняет его рядом с результатами
+func (br *BenchmarkRunner) writeReport() []storage.Artifact {
+	br.resultsMu.Lock()
+	summary := report.Summarize(filepath.Base(br.resultsBase), br.results)
+	br.resultsMu.Unlock()
+
+	fmt.Println()
+	if err := report.WriteTable(os.Stdout, summary); err != nil {
+		log.Printf("ошибка вывода отчета: %v", err)
+	}
+	fmt.Println()
+
+	paths, err := report.SaveFiles(br.resultsBase, summary)
+	if err != nil {
+		log.Printf("ошибка сохранения отчета: %v", err)
+	}
+
+	var artifacts []storage.Artifact
+	for _, path := range paths {
+		artifacts = append(artifacts, storage.FileArtifact(path))
+	}
+
+	if len(paths) > 0 {
+		log.Printf("отчет записан в: %s", strings.Join(paths, ", "))
+	}
+
+	return artifacts
+}
+
+func (br *BenchmarkRunner) manifestPath() string {
+	return manifestPath(br.resultsBase)
+}
+
+func manifestPath(resultsBase string) string {
+	return resultsBase + "_manifest.json"
+}
+
+// finishManifest записывает итоговый манифест запуска
+func (br *BenchmarkRunner) finishManifest(status string, err error) []storage.Artifact {
+	br.manifest.Finish(status, err)
+
+	path := br.manifestPath()
+	if err := br.manifest.Write(path); err != nil {
+		log.Printf("%v", err)
+		return nil
+	}
+
+	log.Printf("манифест запуска записан в: %s", path)
+	return []storage.Artifact{storage.FileArtifact(path)}
+}
+
+// recordEngine запоминает в манифесте версию движка хранилища
+func (br *BenchmarkRunner) recordEngine(wh config.WarehouseConfig, exec executor.QueryExecutor) {
+	ctx, cancel := context.WithTimeout(br.ctx, engineVersionTimeout)
+	defer cancel()
+
+	version, err := executor.EngineVersion(ctx, exec)
+	if err != nil {
+		log.Printf("[%s] не удалось получить версию движка: %v", wh.Name, err)
+	} else {
+		log.Printf("[%s] версия движка: %s", wh.Name, version)
+	}
+
+	br.manifest.SetEngine(wh.Name, version, err)
+}
+
+func (br *BenchmarkRunner) collect(result storage.BenchmarkResult) {
+	br.resultsMu.Lock()
+	defer br.resultsMu.Unlock()
+
+	br.results = append(br.results, result)
+}
+
+func (br *BenchmarkRunner) runSequential(warehouses []config.WarehouseConfig) {
+	for _, wh := range warehouses {
+		if err := br.runWarehouse(wh); err != nil {
+			log.Printf("ERROR: %v хранилище %s", err, wh.Name)
+		}
+	}
+}
+
 func (br *BenchmarkRunner) runWarehouse(wh config.WarehouseConfig) error {
-	schemaName := wh.GetSchemaName(br.cfg.Schema)
+	if br.warehouseDone(wh.Name) {
+		log.Printf("=== хранилище %s: все задачи уже выполнены, пропуск ===", wh.Name)
+		return nil
+	}
 
-	log.Printf("=== хранилище %s (схема %s) ===", wh.Name, schemaName)
+	var session *warehouseSession
 
-	executors := make([]executor.QueryExecutor, br.cfg.Concurrency)
-	for i := 0; i < br.cfg.Concurrency; i++ {
-		exec, err := executor.CreateExecutor(wh, br.connMgr, br.cfg.Schema)
+	if br.budget.exhausted() {
+		log.Printf("=== хранилище %s: бюджет времени исчерпан, задачи записываются как not_run ===", wh.Name)
+		session = br.closedSession(wh)
+	} else {
+		var err error
+		session, err = br.openSession(wh, br.threads())
 		if err != nil {
-
-			for j := 0; j < i; j++ {
-				executors[j].Close()
-			}
-			return fmt.Errorf("ошибка создания экзекьютора: %w", err)
+			br.coverage.add(coverageEntry{
+				warehouse: wh.Name,
+				planned:   br.plannedTasks(),
+				notRun:    max(br.plannedTasks(), 0),
+			})
+			return err
 		}
+	}
+	defer session.close()
+
+	switch {
+	case br.cfg.OpenLoop != nil && br.cfg.OpenLoop.Enabled:
+		br.runOpenLoop(session)
+	case br.cfg.StepLoad != nil && br.cfg.StepLoad.Enabled:
+		br.runStepLoad(session)
+	case br.cfg.Scenario != nil && br.cfg.Scenario.Enabled:
+		br.runScenario(session)
+	default:
+		br.runClosedLoop(session)
+	}
+
+	br.coverage.add(session.coverage())
+
+	log.Printf("=== завершены запросы в хранилище: %s ===", wh.Name)
+	return nil
+
+}
 
-		executors[i] = exec
+// threads - число соединений на хранилище
+func (br *BenchmarkRunner) threads() int {
+	if br.cfg.OpenLoop != nil && br.cfg.OpenLoop.Enabled {
+		return br.cfg.OpenLoop.MaxOutstanding
+	}
+
+	if br.cfg.StepLoad != nil && br.cfg.StepLoad.Enabled {
+		return br.cfg.StepLoad.MaxStep()
+	}
+
+	if br.cfg.Scenario != nil && br.cfg.Scenario.Enabled {
+		return br.cfg.Scenario.Threads(br.cfg.Concurrency)
+	}
+
+	return br.cfg.Concurrency
+}
+
+// plannedTasks - число задач хранилища; unknownTasks для step_duration и сценария,
+// где число проходов зависит от скорости запросов. Для open-loop - total_queries
+// или ожидаемое число поступлений за duration
+func (br *BenchmarkRunner) plannedTasks() int {
+	if sc := br.cfg.Scenario; sc != nil && sc.Enabled {
+		return unknownTasks
+	}
+
+	if ol := br.cfg.OpenLoop; ol != nil && ol.Enabled {
+		if ol.TotalQueries > 0 {
+			return ol.TotalQueries
+		}
 
+		return int(ol.ArrivalRate * parseOptionalDuration(ol.Duration).Minutes())
 	}
 
-	defer func() {
-		for _, exec := range executors {
-			exec.Close()
+	if sl := br.cfg.StepLoad; sl != nil && sl.Enabled {
+		if sl.StepDuration != "" {
+			return unknownTasks
 		}
-	}()
 
-	tasksPerThread := len(br.queries) * br.cfg.Runs
-	totalTasks := tasksPerThread * br.cfg.Concurrency
+		threads := 0
+		for _, step := range sl.Steps {
+			threads += step
+		}
+
+		return len(br.queries) * sl.StepRounds * threads
+	}
+
+	return len(br.queries) * br.cfg.Runs * br.cfg.Concurrency
+}
+
+// runClosedLoop - каждый поток выполняет все запросы по runs раз подряд
+func (br *BenchmarkRunner) runClosedLoop(session *warehouseSession) {
+	wh := session.wh
 
-	log.Printf("запросов: %d, runs: %d, потоков: %d => всего задач: %d",
+	session.totalTasks = br.plannedTasks()
+
+	log.Printf("[%s] запросов: %d, runs: %d, потоков: %d => всего задач: %d",
+		wh.Name,
 		len(br.queries),
 		br.cfg.Runs,
 		br.cfg.Concurrency,
-		totalTasks,
+		session.totalTasks,
 	)
 
-	var completedMu sync.Mutex
-	completed := 0
+	writer := br.newResultWriter()
 
 	var wg sync.WaitGroup
 
-	resultsChan := make(chan storage.BenchmarkResult, br.cfg.Concurrency*10)
-
-	var writerWg sync.WaitGroup
-	writerWg.Add(1)
-
-	go func() {
-		defer writerWg.Done()
-
-		for result := range resultsChan {
-			if err := br.storage.Save(result); err != nil {
-				log.Printf(
-					"[поток %d] WARNING: ошибка сохранения резульата (query=%s): %v",
-					result.ThreadID,
-					result.QueryID,
-					err,
-				)
-			}
-		}
-	}()
-
-	for threadID := 0; threadID < br.cfg.Concurrency; threadID++ {
+	for threadID := range session.executors {
 		wg.Add(1)
 
-		go func(threadID int, exec executor.QueryExecutor) {
+		go func(threadID int) {
 			defer wg.Done()
 
 			for _, q := range br.queries {
-
 				for run := 1; run <= br.cfg.Runs; run++ {
-					completedMu.Lock()
-					completed++
-					currentProgress := completed
-					completedMu.Unlock()
-
-					log.Printf(
-						"[поток %d][%d/%d] запрос %s запуск %d/%d на %s",
-						threadID,
-						currentProgress,
-						totalTasks,
-						q.ID,
-						run,
-						br.cfg.Runs,
-						wh.Name,
-					)
-
-					result := br.executeQuery(exec, q, schemaName, wh.Name, run, threadID)
-
-					resultsChan <- result
-
-					if result.Status == "success" {
-						log.Printf("[поток %d] запрос завершен за %d ms (%d строк)",
-							threadID,
-							result.DurationMs,
-							result.RowCount,
-						)
-					} else {
-						log.Printf("[поток %d] * %s ошибка: %s",
-							threadID,
-							q.ID,
-							result.ErrorMsg,
-						)
-					}
+					br.runTask(session, writer, task{query: q, run: run, threadID: threadID})
 				}
-
 			}
-			log.Printf("[поток %d] завершил все свои задачи", threadID)
-		}(threadID, executors[threadID])
+
+			log.Printf("[%s][поток %d] завершил все свои задачи", wh.Name, threadID)
+		}(threadID)
 	}
 
 	wg.Wait()
 
-	close(resultsChan)
+	writer.close()
+}
 
-	writerWg.Wait()
+// executeQuery выполняет запрос, повторяя неудачные попытки по политике класса ошибки
+func (br *BenchmarkRunner) executeQuery(s *warehouseSession, t task) storage.BenchmarkResult {
+	for attempt := 1; ; attempt++ {
+		result := br.executeAttempt(s, t)
+		result.Attempt = attempt
 
-	log.Printf("=== завершены запросы в хранилище: %s ===", wh.Name)
-	return nil
+		if result.Status == storage.StatusSuccess || result.Status == storage.StatusNotRun {
+			return result
+		}
+
+		policy := br.cfg.RetryPolicy(result.ErrorClass)
+		if attempt >= policy.MaxAttempts || s.stopped() {
+			return result
+		}
 
+		delay := policy.Delay(attempt)
+
+		log.Printf("[%s][поток %d] %s: ошибка класса %s (попытка %d/%d), повтор через %v: %s",
+			s.wh.Name,
+			t.threadID,
+			t.query.ID,
+			result.ErrorClass,
+			attempt,
+			policy.MaxAttempts,
+			delay,
+			result.ErrorMsg,
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-s.ctx.Done():
+			return result
+		}
+	}
 }
 
-func (br *BenchmarkRunner) executeQuery(
-	exec executor.QueryExecutor,
-	q query.Query,
-	schema string,
-	warehouseName string,
-	runNumber int,
-	threadID int,
-) storage.BenchmarkResult {
-	ctx, cancel := context.WithTimeout(context.Background(), br.timeout)
+func (br *BenchmarkRunner) executeAttempt(s *warehouseSession, t task) storage.BenchmarkResult {
+	timeout := br.timeout
+	if t.timeout > 0 {
+		timeout = t.timeout
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
 	defer cancel()
 
-	result := storage.BenchmarkResult{
-		SaveResultTimestamp: time.Now(),
-		QueryID:             q.ID,
-		Warehouse:           warehouseName,
-		Schema:              schema,
-		RunNumber:           runNumber,
-		ThreadID:            threadID,
+	q := t.query
+	result := newResult(s, t)
+
+	statements := q.StatementsFor(s.wh.Type)
+	if len(statements) == 0 {
+		statements = []string{q.SQL}
+	}
+
+	queryResult, err := executor.ExecuteStatements(ctx, s.executors[t.threadID], statements, s.schema)
+	if queryResult != nil {
+		result.Reconnected = queryResult.Reconnected
+		result.EngineMetrics = queryResult.Metrics
+		for _, part := range queryResult.Parts {
+			result.PartDurationsMs = append(result.PartDurationsMs, int(part.Duration.Milliseconds()))
+			result.PartRowCounts = append(result.PartRowCounts, part.RowCount)
+			result.PartMetrics = append(result.PartMetrics, part.Metrics)
+		}
 	}
 
-	queryResult, err := exec.Execute(ctx, q.SQL, schema)
 	if err != nil {
-		result.StartTimestamp = queryResult.StartTimestamp
-		result.EndTimestamp = queryResult.EndTimestamp
-		result.Status = "error"
+		if queryResult != nil {
+			result.StartTimestamp = queryResult.StartTimestamp
+			result.EndTimestamp = queryResult.EndTimestamp
+		}
+		result.Status = storage.StatusError
 		result.ErrorMsg = fmt.Sprintf("ошибка выполнения запроса: %v", err)
-		return result
+		result.ErrorClass = string(executor.ClassifyError(err))
+		return br.checkDeadline(s, result)
 	}
 
 	if !queryResult.Success {
+		queryErr := queryResult.Err
+		if queryErr == nil {
+			queryErr = errors.New(queryResult.Error)
+		}
+
 		result.StartTimestamp = queryResult.StartTimestamp
 		result.EndTimestamp = queryResult.EndTimestamp
-		result.Status = "error"
+		result.Status = storage.StatusError
 		result.ErrorMsg = queryResult.Error
+		result.ErrorClass = string(executor.ClassifyError(queryErr))
 		result.DurationMs = int(queryResult.Duration.Milliseconds())
-		return result
+		return br.checkDeadline(s, result)
 	}
 
 	result.StartTimestamp = queryResult.StartTimestamp
 	result.EndTimestamp = queryResult.EndTimestamp
-	result.Status = "success"
+	result.Status = storage.StatusSuccess
 	result.DurationMs = int(queryResult.Duration.Milliseconds())
 	result.RowCount = queryResult.RowCount
 
+	if q.ExpectedRows != nil && !queryResult.RowCountUnknown && queryResult.RowCount != *q.ExpectedRows {
+		result.Status = storage.StatusError
+		result.ErrorClass = string(executor.ErrorClassWrongResult)
+		result.ErrorMsg = fmt.Sprintf("ожидалось %d строк, получено %d", *q.ExpectedRows, queryResult.RowCount)
+	}
+
 	return result
 }
 
+// checkDeadline - запрос, прерванный жестким дедлайном бюджета, не выполнен, а не упал
+func (br *BenchmarkRunner) checkDeadline(s *warehouseSession, result storage.BenchmarkResult) storage.BenchmarkResult {
+	if s.ctx.Err() == nil {
+		return result
+	}
+
+	result.Status = storage.StatusNotRun
+	result.ErrorClass = string(executor.ErrorClassCancelled)
+	result.ErrorMsg = fmt.Sprintf("прерван по дедлайну бюджета времени: %s", result.ErrorMsg)
+
+	return result
+}
+
+func suiteLoader(suite config.SuiteConfig) *query.QueryLoader {
+	return query.NewSuiteLoader(suite.Paths, query.Selection{
+		IDs:     suite.Queries,
+		Include: suite.Include,
+		Exclude: suite.Exclude,
+		Tags:    suite.Tags,
+	})
+}
+
+// Close - прерывание по сигналу
 func (br *BenchmarkRunner) Close() error {
-	return br.storage.Close()
+	if br.history != nil {
+		br.history.Interrupt()
+	}
+
+	err := br.sink.Close()
+
+	artifacts := br.finishManifest(manifest.StatusInterrupted, nil)
+	if br.s3 != nil {
+		uploadArtifacts(br.s3, artifacts)
+	}
+
+	return err
 }
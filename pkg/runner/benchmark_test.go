@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// fakeUploader не загружает файлы из fail и запоминает все попытки
+type fakeUploader struct {
+	fail     map[string]bool
+	uploaded []string
+}
+
+func (f *fakeUploader) UploadAs(filePath, key string) error {
+	f.uploaded = append(f.uploaded, key)
+
+	if f.fail[filePath] {
+		return errors.New("access denied")
+	}
+
+	return nil
+}
+
+func TestUploadArtifactsContinuesAfterFailure(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	artifacts := []storage.Artifact{
+		storage.FileArtifact("results/run.csv"),
+		storage.FileArtifact("results/run.jsonl"),
+		{Path: "results/parquet/part.parquet", Key: "run_date=2026-01-02/warehouse=trino/part.parquet"},
+		storage.FileArtifact("results/run_manifest.json"),
+	}
+
+	up := &fakeUploader{fail: map[string]bool{"results/run.csv": true}}
+
+	if failed := uploadArtifacts(up, artifacts); failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+
+	want := []string{"run.csv", "run.jsonl", "run_date=2026-01-02/warehouse=trino/part.parquet", "run_manifest.json"}
+	if !slices.Equal(up.uploaded, want) {
+		t.Errorf("uploaded %q, want %q", up.uploaded, want)
+	}
+
+	out := buf.String()
+	for _, s := range []string{"results/run.csv", "не загружено в s3 1 из 4 файлов"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("log lacks %q:\n%s", s, out)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package runner
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// budget - мягкий дедлайн (новые запросы не запускаются) и жесткий
+// (выполняющиеся запросы отменяются); нулевое время - без ограничения
+type budget struct {
+	soft time.Time
+	hard time.Time
+}
+
+func newBudget(start time.Time, maxDuration, grace time.Duration) budget {
+	if maxDuration <= 0 {
+		return budget{}
+	}
+
+	soft := start.Add(maxDuration)
+
+	return budget{
+		soft: soft,
+		hard: soft.Add(grace),
+	}
+}
+
+func (b budget) exhausted() bool {
+	return !b.soft.IsZero() && !time.Now().Before(b.soft)
+}
+
+// tighter возвращает более строгий из двух бюджетов
+func (b budget) tighter(other budget) budget {
+	if b.soft.IsZero() || (!other.soft.IsZero() && other.soft.Before(b.soft)) {
+		b.soft = other.soft
+	}
+
+	if b.hard.IsZero() || (!other.hard.IsZero() && other.hard.Before(b.hard)) {
+		b.hard = other.hard
+	}
+
+	return b
+}
+
+func (b budget) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if b.hard.IsZero() {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithDeadline(parent, b.hard)
+}
+
+func parseOptionalDuration(value string) time.Duration {
+	d, _ := time.ParseDuration(value)
+	return d
+}
+
+// unknownTasks - число задач заранее неизвестно (step_load со step_duration, сценарий)
+const unknownTasks = -1
+
+type coverageEntry struct {
+	warehouse string
+
+	// unknownTasks, если хранилище не выполнялось, а число задач зависело от времени
+	planned int
+	notRun  int
+}
+
+// coverage - какая часть матрицы (запрос x запуск x поток) выполнена по каждому хранилищу
+type coverage struct {
+	mu      sync.Mutex
+	entries []coverageEntry
+}
+
+func (c *coverage) add(entry coverageEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = append(c.entries, entry)
+}
+
+func (c *coverage) log() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	planned, notRun, unknown := 0, 0, 0
+
+	for _, e := range c.entries {
+		if e.planned == unknownTasks {
+			unknown++
+			log.Printf("покрытие %s: хранилище не выполнялось, число задач заранее неизвестно (step_duration, scenario)", e.warehouse)
+			continue
+		}
+
+		planned += e.planned
+		notRun += e.notRun
+
+		log.Printf("покрытие %s: %d/%d задач (%.1f%%), не выполнено: %d",
+			e.warehouse,
+			e.planned-e.notRun,
+			e.planned,
+			percent(e.planned-e.notRun, e.planned),
+			e.notRun,
+		)
+	}
+
+	log.Printf("покрытие матрицы: %d/%d задач (%.1f%%)", planned-notRun, planned, percent(planned-notRun, planned))
+
+	if unknown > 0 {
+		log.Printf("покрытие матрицы не учитывает %d хранилищ с неизвестным числом задач", unknown)
+	}
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+
+	return float64(part) * 100 / float64(total)
+}
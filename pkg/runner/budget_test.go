@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/query"
+)
+
+func TestPlannedTasks(t *testing.T) {
+	queries := []query.Query{{ID: "q1"}, {ID: "q2"}}
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want int
+	}{
+		{"closed loop", config.Config{Runs: 3, Concurrency: 2}, 2 * 3 * 2},
+		{"step rounds", config.Config{StepLoad: &config.StepLoadConfig{Enabled: true, Steps: []int{1, 2, 4}, StepRounds: 2}}, 2 * 2 * 7},
+		{"step duration", config.Config{StepLoad: &config.StepLoadConfig{Enabled: true, Steps: []int{1, 2}, StepDuration: "1m"}}, unknownTasks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := &BenchmarkRunner{cfg: &tt.cfg, queries: queries}
+			if got := br.plannedTasks(); got != tt.want {
+				t.Errorf("plannedTasks() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverageLogSkipsUnknownPlans(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	var c coverage
+	c.add(coverageEntry{warehouse: "a", planned: 10, notRun: 4})
+	c.add(coverageEntry{warehouse: "b", planned: unknownTasks})
+	c.log()
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"покрытие a: 6/10 задач (60.0%), не выполнено: 4",
+		"покрытие b: хранилище не выполнялось, число задач заранее неизвестно",
+		"покрытие матрицы: 6/10 задач (60.0%)",
+		"не учитывает 1 хранилищ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSessionProgress(t *testing.T) {
+	s := &warehouseSession{totalTasks: 12}
+	if got := s.progress(3); got != "3/12" {
+		t.Errorf("progress = %q", got)
+	}
+
+	s.totalTasks = unknownTasks
+	if got := s.progress(3); got != "3" {
+		t.Errorf("progress with unknown total = %q", got)
+	}
+}
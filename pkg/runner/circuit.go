@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// circuitBreaker считает ошибки подряд по каждому запросу в пределах хранилища
+type circuitBreaker struct {
+	threshold int
+	classes   map[string]bool
+
+	mu       sync.Mutex
+	failures map[string]int
+	reasons  map[string]string
+}
+
+func newCircuitBreaker(cfg *config.CircuitBreakerConfig) *circuitBreaker {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	classes := make(map[string]bool, len(cfg.ErrorClasses))
+	for _, class := range cfg.ErrorClasses {
+		classes[class] = true
+	}
+
+	return &circuitBreaker{
+		threshold: cfg.Threshold,
+		classes:   classes,
+		failures:  make(map[string]int),
+		reasons:   make(map[string]string),
+	}
+}
+
+func (cb *circuitBreaker) isOpen(queryID string) bool {
+	if cb == nil {
+		return false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.failures[queryID] >= cb.threshold
+}
+
+// record возвращает true, если этот результат открыл breaker
+func (cb *circuitBreaker) record(result storage.BenchmarkResult) bool {
+	if cb == nil {
+		return false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures[result.QueryID] >= cb.threshold {
+		return false
+	}
+
+	if result.Status == storage.StatusSuccess || !cb.classes[result.ErrorClass] {
+		cb.failures[result.QueryID] = 0
+		return false
+	}
+
+	cb.failures[result.QueryID]++
+	cb.reasons[result.QueryID] = result.ErrorClass
+
+	return cb.failures[result.QueryID] >= cb.threshold
+}
+
+func (cb *circuitBreaker) skippedResult(s *warehouseSession, t task) storage.BenchmarkResult {
+	cb.mu.Lock()
+	reason := cb.reasons[t.query.ID]
+	cb.mu.Unlock()
+
+	result := newResult(s, t)
+	result.Status = storage.StatusSkippedCircuitOpen
+	result.ErrorMsg = fmt.Sprintf("пропущен: %d ошибок %s подряд", cb.threshold, reason)
+	result.ErrorClass = reason
+
+	return result
+}
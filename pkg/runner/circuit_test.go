@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/executor"
+	"tpcds_benchmark/pkg/query"
+	"tpcds_benchmark/pkg/storage"
+)
+
+func failure(queryID, class string) storage.BenchmarkResult {
+	return storage.BenchmarkResult{QueryID: queryID, Status: storage.StatusError, ErrorClass: class}
+}
+
+func TestCircuitBreakerTripsAfterThreshold(t *testing.T) {
+	cb := newCircuitBreaker(&config.CircuitBreakerConfig{Enabled: true, Threshold: 3, ErrorClasses: []string{"timeout"}})
+
+	for i := 1; i <= 3; i++ {
+		if cb.isOpen("q1") {
+			t.Fatalf("open before threshold after %d failures", i-1)
+		}
+
+		// открывает только ошибка, на которой достигнут порог
+		if opened := cb.record(failure("q1", "timeout")); opened != (i == 3) {
+			t.Errorf("record #%d returned %v", i, opened)
+		}
+	}
+
+	if !cb.isOpen("q1") {
+		t.Fatal("breaker not open after 3 failures")
+	}
+
+	if cb.isOpen("q2") {
+		t.Error("breaker is per query, q2 must stay closed")
+	}
+
+	// после открытия ни ошибки, ни успех не меняют состояние
+	if cb.record(failure("q1", "timeout")) || cb.record(storage.BenchmarkResult{QueryID: "q1", Status: storage.StatusSuccess}) {
+		t.Error("record on open breaker reported opening again")
+	}
+	if !cb.isOpen("q1") {
+		t.Error("open breaker closed by a later result")
+	}
+}
+
+func TestCircuitBreakerResets(t *testing.T) {
+	cb := newCircuitBreaker(&config.CircuitBreakerConfig{Enabled: true, Threshold: 2, ErrorClasses: []string{"timeout", "syntax"}})
+
+	tests := []struct {
+		name  string
+		reset storage.BenchmarkResult
+	}{
+		{"success", storage.BenchmarkResult{QueryID: "q1", Status: storage.StatusSuccess}},
+		{"error of other class", failure("q1", "connection")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb.record(failure("q1", "timeout"))
+			cb.record(tt.reset)
+
+			// счет начинается заново: одна ошибка после сброса не открывает
+			if cb.record(failure("q1", "syntax")) || cb.isOpen("q1") {
+				t.Errorf("failures counted across %s", tt.name)
+			}
+
+			cb.record(storage.BenchmarkResult{QueryID: "q1", Status: storage.StatusSuccess})
+		})
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	for _, cfg := range []*config.CircuitBreakerConfig{nil, {Enabled: false, Threshold: 1, ErrorClasses: []string{"timeout"}}} {
+		cb := newCircuitBreaker(cfg)
+
+		if cb.record(failure("q1", "timeout")) || cb.isOpen("q1") {
+			t.Errorf("disabled breaker (%+v) opened", cfg)
+		}
+	}
+}
+
+func TestRunTaskSkipsOpenCircuit(t *testing.T) {
+	br := testRunner(&config.Config{
+		Concurrency:    1,
+		CircuitBreaker: &config.CircuitBreakerConfig{Enabled: true, Threshold: 1, ErrorClasses: []string{"timeout"}},
+	})
+
+	exec := &fakeExecutor{}
+	session := br.newSession(config.WarehouseConfig{Name: "trino", Type: "trino"}, []executor.QueryExecutor{exec})
+	session.breaker.record(failure("q1", "timeout"))
+
+	writer := br.newResultWriter()
+	result, ok := br.runTask(session, writer, task{query: query.Query{ID: "q1", SQL: "select 1"}, run: 2})
+	writer.close()
+
+	if !ok || result.Status != storage.StatusSkippedCircuitOpen || result.ErrorClass != "timeout" || result.RunNumber != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if !strings.Contains(result.ErrorMsg, "1 ошибок timeout подряд") {
+		t.Errorf("skip reason: %q", result.ErrorMsg)
+	}
+
+	if exec.calls != 0 {
+		t.Errorf("query executed %d times with open breaker", exec.calls)
+	}
+
+	saved := br.sink.(*memorySink).results
+	if len(saved) != 1 || saved[0].Status != storage.StatusSkippedCircuitOpen {
+		t.Errorf("skipped row not written: %+v", saved)
+	}
+}
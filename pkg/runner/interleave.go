@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/query"
+)
+
+type interleavedStep struct {
+	query query.Query
+	run   int
+}
+
+// runInterleaved держит открытыми экзекьюторы всех хранилищ и выполняет
+// каждый (запрос, запуск) на всех хранилищах подряд, чтобы сравнение
+// шло при близкой нагрузке на кластер
+func (br *BenchmarkRunner) runInterleaved(warehouses []config.WarehouseConfig) {
+	var sessions []*warehouseSession
+
+	for _, wh := range warehouses {
+		if br.warehouseDone(wh.Name) {
+			log.Printf("=== хранилище %s: все задачи уже выполнены, пропуск ===", wh.Name)
+			continue
+		}
+
+		session, err := br.openSession(wh, br.cfg.Concurrency)
+		if err != nil {
+			log.Printf("ERROR: %v хранилище %s", err, wh.Name)
+			br.coverage.add(coverageEntry{
+				warehouse: wh.Name,
+				planned:   br.plannedTasks(),
+				notRun:    max(br.plannedTasks(), 0),
+			})
+			continue
+		}
+
+		session.totalTasks = br.plannedTasks()
+		sessions = append(sessions, session)
+	}
+
+	defer func() {
+		for _, session := range sessions {
+			session.close()
+		}
+	}()
+
+	if len(sessions) == 0 {
+		return
+	}
+
+	steps := make([]interleavedStep, 0, len(br.queries)*br.cfg.Runs)
+	for _, q := range br.queries {
+		for run := 1; run <= br.cfg.Runs; run++ {
+			steps = append(steps, interleavedStep{query: q, run: run})
+		}
+	}
+
+	var rnd *rand.Rand
+	if br.cfg.ExecutionOrder == config.OrderRandom {
+		seed := br.cfg.OrderSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		log.Printf("случайный порядок выполнения, order_seed: %d", seed)
+
+		rnd = rand.New(rand.NewSource(seed))
+		rnd.Shuffle(len(steps), func(i, j int) {
+			steps[i], steps[j] = steps[j], steps[i]
+		})
+	}
+
+	log.Printf("чередование: %d шагов на %d хранилищах", len(steps), len(sessions))
+
+	writer := br.newResultWriter()
+
+	order := make([]*warehouseSession, len(sessions))
+	copy(order, sessions)
+
+	for _, step := range steps {
+		if rnd != nil {
+			rnd.Shuffle(len(order), func(i, j int) {
+				order[i], order[j] = order[j], order[i]
+			})
+		}
+
+		for _, session := range order {
+			var wg sync.WaitGroup
+
+			for threadID := range session.executors {
+				wg.Add(1)
+
+				go func(threadID int) {
+					defer wg.Done()
+
+					br.runTask(session, writer, task{query: step.query, run: step.run, threadID: threadID})
+				}(threadID)
+			}
+
+			wg.Wait()
+		}
+	}
+
+	writer.close()
+
+	for _, session := range sessions {
+		br.coverage.add(session.coverage())
+	}
+
+	log.Printf("=== чередование завершено ===")
+}
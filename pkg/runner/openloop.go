@@ -0,0 +1,204 @@
+package runner
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/query"
+)
+
+// weightedQueries выбирает запрос пропорционально весу
+type weightedQueries struct {
+	queries    []query.Query
+	cumulative []float64
+}
+
+func newWeightedQueries(queries []query.Query, weights map[string]float64) (*weightedQueries, error) {
+	wq := &weightedQueries{}
+	total := 0.0
+
+	known := make(map[string]bool, len(queries))
+
+	for _, q := range queries {
+		known[q.ID] = true
+
+		weight, ok := weights[q.ID]
+		if !ok {
+			weight = 1
+		}
+
+		if weight <= 0 {
+			continue
+		}
+
+		total += weight
+		wq.queries = append(wq.queries, q)
+		wq.cumulative = append(wq.cumulative, total)
+	}
+
+	for id := range weights {
+		if !known[id] {
+			log.Printf("WARNING: вес задан для неизвестного запроса %s", id)
+		}
+	}
+
+	if len(wq.queries) == 0 {
+		return nil, fmt.Errorf("нет запросов с положительным весом")
+	}
+
+	return wq, nil
+}
+
+func (wq *weightedQueries) pick(rnd *rand.Rand) query.Query {
+	x := rnd.Float64() * wq.cumulative[len(wq.cumulative)-1]
+	i := sort.SearchFloat64s(wq.cumulative, x)
+	if i >= len(wq.queries) {
+		i = len(wq.queries) - 1
+	}
+
+	return wq.queries[i]
+}
+
+// arrivals генерирует плановые моменты поступления запросов
+type arrivals struct {
+	interval time.Duration
+	poisson  bool
+	rnd      *rand.Rand
+	next     time.Time
+}
+
+func newArrivals(cfg *config.OpenLoopConfig, start time.Time, rnd *rand.Rand) *arrivals {
+	return &arrivals{
+		interval: time.Duration(float64(time.Minute) / cfg.ArrivalRate),
+		poisson:  cfg.Distribution == config.ArrivalPoisson,
+		rnd:      rnd,
+		next:     start,
+	}
+}
+
+func (a *arrivals) advance() time.Time {
+	current := a.next
+
+	gap := a.interval
+	if a.poisson {
+		gap = time.Duration(a.rnd.ExpFloat64() * float64(a.interval))
+	}
+
+	a.next = a.next.Add(gap)
+	return current
+}
+
+// runOpenLoop подает запросы с заданной частотой независимо от их завершения;
+// если все max_outstanding соединений заняты, запрос ждет в очереди,
+// и это ожидание записывается как queue_delay_ms
+func (br *BenchmarkRunner) runOpenLoop(session *warehouseSession) {
+	cfg := br.cfg.OpenLoop
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	picker, err := newWeightedQueries(br.queries, cfg.QueryWeights)
+	if err != nil {
+		log.Printf("ERROR: %v хранилище %s", err, session.wh.Name)
+		return
+	}
+
+	start := time.Now()
+
+	var end time.Time
+	if cfg.Duration != "" {
+		end = start.Add(parseOptionalDuration(cfg.Duration))
+	}
+
+	session.totalTasks = br.plannedTasks()
+
+	log.Printf("[%s] open-loop: %.1f запросов/мин (%s), до %d одновременно, seed %d, ~%d запросов",
+		session.wh.Name,
+		cfg.ArrivalRate,
+		cfg.Distribution,
+		len(session.executors),
+		seed,
+		session.totalTasks,
+	)
+
+	free := make(chan int, len(session.executors))
+	for threadID := range session.executors {
+		free <- threadID
+	}
+
+	writer := br.newResultWriter()
+	schedule := newArrivals(cfg, start, rnd)
+
+	var (
+		wg         sync.WaitGroup
+		dispatched int
+		truncated  bool
+	)
+
+	for seq := 1; cfg.TotalQueries == 0 || seq <= cfg.TotalQueries; seq++ {
+		scheduled := schedule.advance()
+
+		if !end.IsZero() && !scheduled.Before(end) {
+			break
+		}
+
+		if session.stopped() {
+			truncated = true
+			break
+		}
+
+		if wait := time.Until(scheduled); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-session.ctx.Done():
+			}
+		}
+
+		q := picker.pick(rnd)
+
+		var threadID int
+		select {
+		case threadID = <-free:
+		case <-session.ctx.Done():
+			threadID = -1
+		}
+
+		if threadID < 0 {
+			truncated = true
+			break
+		}
+
+		dispatched++
+		wg.Add(1)
+
+		go func(t task) {
+			defer wg.Done()
+			defer func() { free <- t.threadID }()
+
+			br.runTask(session, writer, t)
+		}(task{query: q, run: seq, threadID: threadID, scheduled: scheduled})
+	}
+
+	wg.Wait()
+	writer.close()
+
+	// не поданные из-за бюджета или остановки запросы - тоже не выполненные задачи
+	if truncated && dispatched < session.totalTasks {
+		session.mu.Lock()
+		session.notRun += session.totalTasks - dispatched
+		session.mu.Unlock()
+
+		log.Printf("[%s] open-loop остановлен досрочно: подано %d из ~%d запросов",
+			session.wh.Name,
+			dispatched,
+			session.totalTasks,
+		)
+	}
+}
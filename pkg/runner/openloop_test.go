@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/executor"
+	"tpcds_benchmark/pkg/query"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// fakeExecutor выполняет любой запрос успешно и вызывает onExecute с номером вызова
+type fakeExecutor struct {
+	mu        sync.Mutex
+	calls     int
+	onExecute func(n int)
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, query string, schema string) (*executor.QueryResult, error) {
+	f.mu.Lock()
+	f.calls++
+	n := f.calls
+	f.mu.Unlock()
+
+	if f.onExecute != nil {
+		f.onExecute(n)
+	}
+
+	now := time.Now()
+	return &executor.QueryResult{StartTimestamp: now, EndTimestamp: now, Success: true}, nil
+}
+
+func (f *fakeExecutor) Name() string { return "fake" }
+
+func (f *fakeExecutor) Close() error { return nil }
+
+// memorySink запоминает сохраненные результаты
+type memorySink struct {
+	mu      sync.Mutex
+	results []storage.BenchmarkResult
+}
+
+func (m *memorySink) Open() error { return nil }
+
+func (m *memorySink) Save(result storage.BenchmarkResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.results = append(m.results, result)
+	return nil
+}
+
+func (m *memorySink) Flush() error { return nil }
+
+func (m *memorySink) Close() error { return nil }
+
+func (m *memorySink) Artifacts() []storage.Artifact { return nil }
+
+func testRunner(cfg *config.Config, queries ...query.Query) *BenchmarkRunner {
+	return &BenchmarkRunner{
+		cfg:     cfg,
+		sink:    &memorySink{},
+		queries: queries,
+		timeout: time.Minute,
+		ctx:     context.Background(),
+	}
+}
+
+func TestOpenLoopStoppedEarlyCountsUndispatched(t *testing.T) {
+	cfg := &config.Config{
+		Concurrency: 1,
+		OpenLoop: &config.OpenLoopConfig{
+			Enabled:        true,
+			ArrivalRate:    600000,
+			Distribution:   config.ArrivalConstant,
+			MaxOutstanding: 1,
+			TotalQueries:   10,
+			Seed:           1,
+		},
+	}
+
+	br := testRunner(cfg, query.Query{ID: "q1", SQL: "select 1"})
+
+	exec := &fakeExecutor{}
+	session := br.newSession(config.WarehouseConfig{Name: "trino", Type: "trino"}, []executor.QueryExecutor{exec})
+
+	// остановка (сигнал, жесткий дедлайн) после третьего запроса
+	exec.onExecute = func(n int) {
+		if n == 3 {
+			session.cancel()
+		}
+	}
+
+	br.runOpenLoop(session)
+
+	c := session.coverage()
+	if c.planned != 10 {
+		t.Fatalf("planned = %d, want 10", c.planned)
+	}
+
+	if done := c.planned - c.notRun; done != 3 {
+		t.Errorf("coverage reports %d/%d done (not run %d), want 3 executed", done, c.planned, c.notRun)
+	}
+
+	succeeded := 0
+	for _, r := range br.results {
+		if r.Status == storage.StatusSuccess {
+			succeeded++
+		}
+	}
+
+	if succeeded != 3 {
+		t.Errorf("expected 3 successful results, got %d", succeeded)
+	}
+}
+
+func TestPlannedTasksOpenLoop(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.OpenLoopConfig
+		want int
+	}{
+		{"total queries", config.OpenLoopConfig{Enabled: true, ArrivalRate: 60, TotalQueries: 25, Duration: "1h"}, 25},
+		{"rate times duration", config.OpenLoopConfig{Enabled: true, ArrivalRate: 30, Duration: "10m"}, 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := testRunner(&config.Config{Runs: 1, Concurrency: 1, OpenLoop: &tt.cfg}, query.Query{ID: "q1"})
+			if got := br.plannedTasks(); got != tt.want {
+				t.Errorf("plannedTasks() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"log"
+	"sync"
+	"tpcds_benchmark/pkg/config"
+)
+
+type warehouseGroup struct {
+	name       string
+	warehouses []config.WarehouseConfig
+}
+
+// groupWarehouses сохраняет порядок групп и хранилищ из конфига,
+// хранилища без parallel_group попадают в общую группу
+func groupWarehouses(warehouses []config.WarehouseConfig) []warehouseGroup {
+	var groups []warehouseGroup
+	index := make(map[string]int)
+
+	for _, wh := range warehouses {
+		i, ok := index[wh.ParallelGroup]
+		if !ok {
+			i = len(groups)
+			index[wh.ParallelGroup] = i
+			groups = append(groups, warehouseGroup{name: wh.ParallelGroup})
+		}
+
+		groups[i].warehouses = append(groups[i].warehouses, wh)
+	}
+
+	return groups
+}
+
+func (br *BenchmarkRunner) runParallel(warehouses []config.WarehouseConfig) {
+	groups := groupWarehouses(warehouses)
+
+	log.Printf("параллельный запуск: %d групп хранилищ", len(groups))
+
+	var wg sync.WaitGroup
+
+	for _, group := range groups {
+		names := make([]string, 0, len(group.warehouses))
+		for _, wh := range group.warehouses {
+			names = append(names, wh.Name)
+		}
+
+		groupName := group.name
+		if groupName == "" {
+			groupName = "default"
+		}
+
+		log.Printf("группа %s: %v", groupName, names)
+
+		wg.Add(1)
+
+		go func(group warehouseGroup) {
+			defer wg.Done()
+
+			br.runSequential(group.warehouses)
+		}(group)
+	}
+
+	wg.Wait()
+}
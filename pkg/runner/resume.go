@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"fmt"
+	"log"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// taskKey однозначно определяет задачу в рамках одного файла результатов
+type taskKey struct {
+	warehouse string
+	queryID   string
+	run       int
+	thread    int
+}
+
+// loadCompleted возвращает задачи, которые уже успешно выполнены в файле результатов,
+// и их строки для итогового отчета. Учитывается последняя строка задачи: задачи с ошибкой
+// или not_run выполняются заново, и в отчет попадет только новый результат.
+// Недописанная последняя строка отрезается до чтения
+func loadCompleted(path string) (map[taskKey]bool, []storage.BenchmarkResult, error) {
+	if err := storage.PrepareResumeCSV(path); err != nil {
+		return nil, nil, fmt.Errorf("ошибка чтения результатов для продолжения: %w", err)
+	}
+
+	results, err := storage.ReadCSVResults(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка чтения результатов для продолжения: %w", err)
+	}
+
+	completed := make(map[taskKey]bool)
+	var done []storage.BenchmarkResult
+
+	for _, r := range storage.LatestResults(results) {
+		if r.Status != storage.StatusSuccess {
+			continue
+		}
+
+		completed[taskKey{
+			warehouse: r.Warehouse,
+			queryID:   r.QueryID,
+			run:       r.RunNumber,
+			thread:    r.ThreadID,
+		}] = true
+		done = append(done, r)
+	}
+
+	log.Printf("продолжение %s: %d строк, %d задач уже выполнены успешно", path, len(results), len(completed))
+
+	return completed, done, nil
+}
+
+func (br *BenchmarkRunner) isCompleted(warehouse, queryID string, run, thread int) bool {
+	return br.completed[taskKey{
+		warehouse: warehouse,
+		queryID:   queryID,
+		run:       run,
+		thread:    thread,
+	}]
+}
+
+// warehouseDone - все задачи хранилища уже выполнены, подключаться не нужно
+func (br *BenchmarkRunner) warehouseDone(warehouse string) bool {
+	if len(br.completed) == 0 {
+		return false
+	}
+
+	for _, q := range br.queries {
+		for run := 1; run <= br.cfg.Runs; run++ {
+			for thread := 0; thread < br.cfg.Concurrency; thread++ {
+				if !br.isCompleted(warehouse, q.ID, run, thread) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"tpcds_benchmark/pkg/storage"
+)
+
+func writeResults(t *testing.T, dir string, results ...storage.BenchmarkResult) string {
+	t.Helper()
+
+	s := storage.NewCSVStorage(dir, "run.csv")
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range results {
+		if err := s.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return s.GetFilePath()
+}
+
+func resumeResult(queryID string, run int, status string) storage.BenchmarkResult {
+	return storage.BenchmarkResult{
+		SaveResultTimestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		QueryID:             queryID,
+		Warehouse:           "trino",
+		RunNumber:           run,
+		Status:              status,
+	}
+}
+
+func TestLoadCompletedKeepsLastRowPerTask(t *testing.T) {
+	path := writeResults(t, t.TempDir(),
+		resumeResult("q1", 1, storage.StatusError),
+		resumeResult("q1", 1, storage.StatusSuccess),
+		resumeResult("q2", 1, storage.StatusSuccess),
+		resumeResult("q3", 1, storage.StatusError),
+		resumeResult("q4", 1, storage.StatusNotRun),
+	)
+
+	completed, results, err := loadCompleted(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"q1", "q2"} {
+		if !completed[taskKey{warehouse: "trino", queryID: id, run: 1}] {
+			t.Errorf("%s should be completed", id)
+		}
+	}
+
+	for _, id := range []string{"q3", "q4"} {
+		if completed[taskKey{warehouse: "trino", queryID: id, run: 1}] {
+			t.Errorf("%s should be re-run", id)
+		}
+	}
+
+	// в отчет - по строке на выполненную задачу, ошибки будут перезаписаны новым запуском
+	if len(results) != 2 || results[0].QueryID != "q1" || results[1].QueryID != "q2" {
+		t.Fatalf("unexpected results for report: %+v", results)
+	}
+
+	for _, r := range results {
+		if r.Status != storage.StatusSuccess {
+			t.Errorf("%s: expected success row, got %s", r.QueryID, r.Status)
+		}
+	}
+}
+
+func TestLoadCompletedTruncatesPartialLine(t *testing.T) {
+	path := writeResults(t, t.TempDir(), resumeResult("q1", 1, storage.StatusSuccess))
+
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// процесс упал посреди записи строки: поля не дописаны, перевода строки нет
+	partial := "2026-01-02T03:04:05Z,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z,q2,trino,,1,0,12"
+	if err := os.WriteFile(path, append(before, partial...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	completed, results, err := loadCompleted(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || len(completed) != 1 || results[0].QueryID != "q1" {
+		t.Fatalf("partial line was parsed: completed=%v results=%+v", completed, results)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(after) != string(before) {
+		t.Errorf("partial line not truncated:\n%s", after)
+	}
+}
+
+func TestLoadCompletedRejectsForeignHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.csv")
+
+	content := "timestamp,query_id,warehouse,status\n2026-01-02T03:04:05Z,q1,trino,success\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := loadCompleted(path)
+	if err == nil || !strings.Contains(err.Error(), "заголовок") {
+		t.Fatalf("expected header mismatch error, got %v", err)
+	}
+}
+
+func TestLoadCompletedReportsMalformedValues(t *testing.T) {
+	path := writeResults(t, t.TempDir(), resumeResult("q1", 1, storage.StatusSuccess))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broken := strings.Replace(string(data), ",q1,trino,,1,", ",q1,trino,,one,", 1)
+	if broken == string(data) {
+		t.Fatal("fixture does not contain the run number column")
+	}
+
+	if err := os.WriteFile(path, []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = loadCompleted(path)
+	if err == nil || !strings.Contains(err.Error(), "run_number") {
+		t.Fatalf("expected run_number parse error, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package runner
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/query"
+	"tpcds_benchmark/pkg/stats"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// queryClass - загруженный класс запросов сценария
+type queryClass struct {
+	name    string
+	queries []query.Query
+	weight  float64
+	threads int
+	timeout time.Duration
+}
+
+func loadClasses(cfg *config.Config, defaultTimeout time.Duration) ([]queryClass, error) {
+	var classes []queryClass
+
+	for _, c := range cfg.Scenario.Classes {
+		loader := query.NewQueryLoader(c.QueriesPath)
+		source := c.QueriesPath
+		if c.Suite != "" {
+			loader = suiteLoader(cfg.Suites[c.Suite])
+			source = "suite " + c.Suite
+		}
+
+		loaded, err := loader.LoadAll()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка загрузки запросов класса %s: %w", c.Name, err)
+		}
+
+		var queries []query.Query
+		for _, q := range loaded {
+			if len(c.Tags) == 0 || q.HasAnyTag(c.Tags) {
+				queries = append(queries, q)
+			}
+		}
+
+		if len(queries) == 0 {
+			return nil, fmt.Errorf("класс %s: нет запросов в %s с тегами %v", c.Name, source, c.Tags)
+		}
+
+		timeout := defaultTimeout
+		if c.Timeout != "" {
+			timeout = parseOptionalDuration(c.Timeout)
+		}
+
+		log.Printf("класс %s: %d запросов из %s", c.Name, len(queries), source)
+
+		classes = append(classes, queryClass{
+			name:    c.Name,
+			queries: queries,
+			weight:  c.Weight,
+			threads: c.Threads,
+			timeout: timeout,
+		})
+	}
+
+	return classes, nil
+}
+
+// classPicker выбирает класс для общего потока пропорционально весу
+type classPicker struct {
+	classes    []*queryClass
+	cumulative []float64
+}
+
+func newClassPicker(classes []queryClass) *classPicker {
+	p := &classPicker{}
+	total := 0.0
+
+	for i := range classes {
+		if classes[i].weight <= 0 {
+			continue
+		}
+
+		total += classes[i].weight
+		p.classes = append(p.classes, &classes[i])
+		p.cumulative = append(p.cumulative, total)
+	}
+
+	return p
+}
+
+func (p *classPicker) pick(rnd *rand.Rand) *queryClass {
+	x := rnd.Float64() * p.cumulative[len(p.cumulative)-1]
+	i := sort.SearchFloat64s(p.cumulative, x)
+	if i >= len(p.classes) {
+		i = len(p.classes) - 1
+	}
+
+	return p.classes[i]
+}
+
+// runScenario - потоки классов с threads выполняют только свой класс, общие потоки
+// (concurrency) выбирают класс по весу; все работают одновременно до конца duration
+func (br *BenchmarkRunner) runScenario(session *warehouseSession) {
+	cfg := br.cfg.Scenario
+	wh := session.wh
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	// nil - общий поток, класс выбирается по весу
+	var assignment []*queryClass
+	for i := range br.classes {
+		for n := 0; n < br.classes[i].threads; n++ {
+			assignment = append(assignment, &br.classes[i])
+		}
+	}
+
+	picker := newClassPicker(br.classes)
+	if len(picker.classes) > 0 {
+		for n := 0; n < br.cfg.Concurrency; n++ {
+			assignment = append(assignment, nil)
+		}
+	}
+
+	end := time.Now().Add(parseOptionalDuration(cfg.Duration))
+
+	// число запросов зависит от их скорости и становится известно только в конце
+	session.mu.Lock()
+	session.totalTasks = unknownTasks
+	session.mu.Unlock()
+
+	log.Printf("[%s] сценарий: %d классов, %d потоков, seed %d, до %s",
+		wh.Name,
+		len(br.classes),
+		len(assignment),
+		seed,
+		end.Format(time.TimeOnly),
+	)
+
+	writer := br.newResultWriter()
+
+	var (
+		mu      sync.Mutex
+		results []storage.BenchmarkResult
+		wg      sync.WaitGroup
+	)
+
+	for threadID, class := range assignment {
+		wg.Add(1)
+
+		go func(threadID int, fixed *queryClass) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(seed + int64(threadID)))
+
+			for seq := 1; time.Now().Before(end) && !session.stopped(); seq++ {
+				class := fixed
+				if class == nil {
+					class = picker.pick(rnd)
+				}
+
+				t := task{
+					query:    class.queries[rnd.Intn(len(class.queries))],
+					run:      seq,
+					threadID: threadID,
+					class:    class.name,
+					timeout:  class.timeout,
+				}
+
+				result, ok := br.runTask(session, writer, t)
+				if !ok {
+					continue
+				}
+
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}(threadID, class)
+	}
+
+	wg.Wait()
+	writer.close()
+
+	// хранилище, на котором бюджет кончился до первого запроса, остается с неизвестным числом задач
+	if len(results) > 0 || !session.stopped() {
+		session.mu.Lock()
+		session.totalTasks = len(results)
+		session.mu.Unlock()
+	}
+
+	logClassLatency(wh.Name, br.classes, results)
+}
+
+// logClassLatency - латентность каждого класса под совместной нагрузкой
+func logClassLatency(warehouse string, classes []queryClass, results []storage.BenchmarkResult) {
+	durations := make(map[string][]float64)
+	failed := make(map[string]int)
+
+	for _, r := range results {
+		switch r.Status {
+		case storage.StatusSuccess:
+			durations[r.QueryClass] = append(durations[r.QueryClass], float64(r.DurationMs))
+		case storage.StatusError:
+			failed[r.QueryClass]++
+		}
+	}
+
+	for _, class := range classes {
+		sorted := stats.Sorted(durations[class.name])
+
+		log.Printf("[%s] класс %s: успешно %d, ошибок %d, p50 %.0f ms, p95 %.0f ms",
+			warehouse,
+			class.name,
+			len(sorted),
+			failed[class.name],
+			stats.Percentile(sorted, 50),
+			stats.Percentile(sorted, 95),
+		)
+	}
+}
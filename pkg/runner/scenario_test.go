@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/executor"
+	"tpcds_benchmark/pkg/query"
+)
+
+func scenarioRunner(duration string) *BenchmarkRunner {
+	cfg := &config.Config{
+		Concurrency: 1,
+		Scenario: &config.ScenarioConfig{
+			Enabled:  true,
+			Duration: duration,
+			Seed:     1,
+			Classes:  []config.QueryClassConfig{{Name: "bi", Threads: 1}},
+		},
+	}
+
+	q := query.Query{ID: "q1", SQL: "select 1"}
+
+	br := testRunner(cfg, q)
+	br.classes = []queryClass{{name: "bi", queries: []query.Query{q}, threads: 1}}
+
+	return br
+}
+
+func TestScenarioProgressUnknownWhileRunning(t *testing.T) {
+	br := scenarioRunner("30ms")
+
+	var (
+		progress atomic.Value
+		calls    atomic.Int32
+	)
+
+	exec := &fakeExecutor{}
+	session := br.newSession(config.WarehouseConfig{Name: "trino", Type: "trino"}, []executor.QueryExecutor{exec})
+
+	exec.onExecute = func(n int) {
+		calls.Add(1)
+		if n == 1 {
+			progress.Store(session.progress(n))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	br.runScenario(session)
+
+	if got := progress.Load(); got != "1" {
+		t.Errorf("progress during scenario = %v, want total-less %q", got, "1")
+	}
+
+	c := session.coverage()
+	if c.planned != int(calls.Load()) || c.notRun != 0 {
+		t.Errorf("coverage after scenario: %+v, executed %d", c, calls.Load())
+	}
+}
+
+func TestScenarioExhaustedBudgetIsUnknown(t *testing.T) {
+	br := scenarioRunner("1h")
+
+	if got := br.plannedTasks(); got != unknownTasks {
+		t.Errorf("plannedTasks() = %d, want unknownTasks", got)
+	}
+
+	// бюджет исчерпан до начала хранилища: ни одного запроса
+	session := br.closedSession(config.WarehouseConfig{Name: "trino", Type: "trino"})
+	br.runScenario(session)
+
+	if c := session.coverage(); c.planned != unknownTasks {
+		t.Errorf("coverage of skipped scenario warehouse: %+v, want unknown planned", c)
+	}
+}
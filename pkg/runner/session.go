@@ -0,0 +1,324 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/executor"
+	"tpcds_benchmark/pkg/query"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// warehouseSession - открытые соединения (по одному на поток) и прогресс хранилища
+type warehouseSession struct {
+	wh        config.WarehouseConfig
+	schema    string
+	suite     string
+	executors []executor.QueryExecutor
+	breaker   *circuitBreaker
+
+	// число активных потоков, меняется только между шагами step-load
+	concurrency int
+
+	// бюджет хранилища с учетом общего; ctx отменяется на жестком дедлайне
+	budget budget
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	completed  int
+	totalTasks int
+	notRun     int
+}
+
+// ограничение на запрос версии движка при открытии сессии
+const engineVersionTimeout = 30 * time.Second
+
+// openSession открывает по соединению на каждый из threads потоков
+func (br *BenchmarkRunner) openSession(wh config.WarehouseConfig, threads int) (*warehouseSession, error) {
+	log.Printf("=== хранилище %s (схема %s) ===", wh.Name, wh.GetSchemaName(br.cfg.Schema))
+
+	executors := make([]executor.QueryExecutor, threads)
+	for i := 0; i < threads; i++ {
+		exec, err := executor.CreateExecutor(wh, br.connMgr, br.cfg.Schema)
+		if err != nil {
+
+			for j := 0; j < i; j++ {
+				executors[j].Close()
+			}
+			return nil, fmt.Errorf("ошибка создания экзекьютора: %w", err)
+		}
+
+		executors[i] = exec
+
+	}
+
+	if threads > 0 {
+		br.recordEngine(wh, executors[0])
+	}
+
+	return br.newSession(wh, executors), nil
+}
+
+func (br *BenchmarkRunner) newSession(wh config.WarehouseConfig, executors []executor.QueryExecutor) *warehouseSession {
+	whBudget := newBudget(time.Now(), parseOptionalDuration(wh.MaxDuration), br.grace)
+	b := br.budget.tighter(whBudget)
+
+	ctx, cancel := b.context(br.ctx)
+
+	return &warehouseSession{
+		wh:        wh,
+		schema:    wh.GetSchemaName(br.cfg.Schema),
+		suite:     br.cfg.Suite,
+		executors: executors,
+		breaker:   newCircuitBreaker(br.cfg.CircuitBreaker),
+		budget:    b,
+		ctx:       ctx,
+		cancel:    cancel,
+
+		concurrency: len(executors),
+	}
+}
+
+// closedSession - сессия без соединений: бюджет уже исчерпан и все задачи
+// будут записаны как not_run
+func (br *BenchmarkRunner) closedSession(wh config.WarehouseConfig) *warehouseSession {
+	s := br.newSession(wh, make([]executor.QueryExecutor, br.threads()))
+	s.cancel()
+	return s
+}
+
+func (s *warehouseSession) close() {
+	s.cancel()
+
+	for _, exec := range s.executors {
+		if exec != nil {
+			exec.Close()
+		}
+	}
+}
+
+// stopped - новые запросы на хранилище больше не запускаются
+func (s *warehouseSession) stopped() bool {
+	return s.budget.exhausted() || s.ctx.Err() != nil
+}
+
+func (s *warehouseSession) coverage() coverageEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return coverageEntry{
+		warehouse: s.wh.Name,
+		planned:   s.totalTasks,
+		notRun:    s.notRun,
+	}
+}
+
+// progress - номер задачи для логов: "n/всего" или "n", если число задач неизвестно
+func (s *warehouseSession) progress(n int) string {
+	s.mu.Lock()
+	total := s.totalTasks
+	s.mu.Unlock()
+
+	if total == unknownTasks {
+		return strconv.Itoa(n)
+	}
+
+	return fmt.Sprintf("%d/%d", n, total)
+}
+
+func (s *warehouseSession) nextProgress() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.completed++
+	return s.completed
+}
+
+// resultWriter - единственная горутина, которая раздает результаты получателям
+type resultWriter struct {
+	results chan storage.BenchmarkResult
+	wg      sync.WaitGroup
+}
+
+func (br *BenchmarkRunner) newResultWriter() *resultWriter {
+	w := &resultWriter{
+		results: make(chan storage.BenchmarkResult, br.cfg.Concurrency*10),
+	}
+
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		for result := range w.results {
+			br.collect(result)
+
+			if err := br.sink.Save(result); err != nil {
+				log.Printf(
+					"[%s][поток %d] WARNING: ошибка сохранения резульата (query=%s): %v",
+					result.Warehouse,
+					result.ThreadID,
+					result.QueryID,
+					err,
+				)
+			}
+
+			// очередь пуста - сбрасываем буферы получателей
+			if len(w.results) == 0 {
+				if err := br.sink.Flush(); err != nil {
+					log.Printf("WARNING: ошибка сброса результатов: %v", err)
+				}
+			}
+		}
+	}()
+
+	return w
+}
+
+func (w *resultWriter) write(result storage.BenchmarkResult) {
+	w.results <- result
+}
+
+func (w *resultWriter) close() {
+	close(w.results)
+	w.wg.Wait()
+}
+
+// task - один запуск запроса на потоке хранилища
+type task struct {
+	query    query.Query
+	run      int
+	threadID int
+
+	// open-loop: плановое время поступления запроса
+	scheduled time.Time
+
+	// сценарий: класс запроса и его таймаут
+	class   string
+	timeout time.Duration
+}
+
+// runTask выполняет один запуск запроса на потоке хранилища и отправляет результат на запись
+func (br *BenchmarkRunner) runTask(s *warehouseSession, writer *resultWriter, t task) (storage.BenchmarkResult, bool) {
+	q, run, threadID := t.query, t.run, t.threadID
+
+	if br.isCompleted(s.wh.Name, q.ID, run, threadID) {
+		log.Printf(
+			"[%s][поток %d][%s] запрос %s запуск %d уже выполнен, пропуск",
+			s.wh.Name,
+			threadID,
+			s.progress(s.nextProgress()),
+			q.ID,
+			run,
+		)
+		return storage.BenchmarkResult{}, false
+	}
+
+	progress := s.nextProgress()
+
+	if s.stopped() {
+		result := br.notRunResult(s, t, "бюджет времени исчерпан")
+		writer.write(result)
+
+		s.mu.Lock()
+		s.notRun++
+		s.mu.Unlock()
+
+		return result, true
+	}
+
+	if s.breaker.isOpen(q.ID) {
+		result := s.breaker.skippedResult(s, t)
+		writer.write(result)
+
+		log.Printf(
+			"[%s][поток %d][%s] запрос %s запуск %d пропущен: circuit breaker открыт",
+			s.wh.Name,
+			threadID,
+			s.progress(progress),
+			q.ID,
+			run,
+		)
+		return result, true
+	}
+
+	log.Printf(
+		"[%s][поток %d][%s] запрос %s запуск %d",
+		s.wh.Name,
+		threadID,
+		s.progress(progress),
+		q.ID,
+		run,
+	)
+
+	dispatched := time.Now()
+
+	result := br.executeQuery(s, t)
+
+	if !t.scheduled.IsZero() {
+		result.QueueDelayMs = int(dispatched.Sub(t.scheduled).Milliseconds())
+	}
+
+	writer.write(result)
+
+	if result.Status == storage.StatusNotRun {
+		s.mu.Lock()
+		s.notRun++
+		s.mu.Unlock()
+	}
+
+	if s.breaker.record(result) {
+		log.Printf("[%s] circuit breaker открыт для %s: %d ошибок %s подряд, оставшиеся запуски будут пропущены",
+			s.wh.Name,
+			q.ID,
+			s.breaker.threshold,
+			result.ErrorClass,
+		)
+	}
+
+	if result.Status == storage.StatusSuccess {
+		log.Printf("[%s][поток %d] запрос завершен за %d ms (%d строк)",
+			s.wh.Name,
+			threadID,
+			result.DurationMs,
+			result.RowCount,
+		)
+	} else {
+		log.Printf("[%s][поток %d] * %s ошибка: %s",
+			s.wh.Name,
+			threadID,
+			q.ID,
+			result.ErrorMsg,
+		)
+	}
+
+	return result, true
+}
+
+// newResult заполняет поля результата, общие для всех статусов
+func newResult(s *warehouseSession, t task) storage.BenchmarkResult {
+	return storage.BenchmarkResult{
+		SaveResultTimestamp: time.Now(),
+		ScheduledTimestamp:  t.scheduled,
+		QueryID:             t.query.ID,
+		Warehouse:           s.wh.Name,
+		Schema:              s.schema,
+		RunNumber:           t.run,
+		ThreadID:            t.threadID,
+		Concurrency:         s.concurrency,
+		QueryClass:          t.class,
+		Suite:               s.suite,
+	}
+}
+
+func (br *BenchmarkRunner) notRunResult(s *warehouseSession, t task, reason string) storage.BenchmarkResult {
+	result := newResult(s, t)
+	result.Status = storage.StatusNotRun
+	result.ErrorMsg = reason
+	return result
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/connection"
+	"tpcds_benchmark/pkg/executor"
+	"tpcds_benchmark/pkg/history"
+	"tpcds_benchmark/pkg/storage"
+	"tpcds_benchmark/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// createSinks открывает получатели результатов из sinks и историю запусков; при продолжении
+// запуска первый csv дописывается в resumePath, остальные получают его имя
+func createSinks(cfg *config.Config, connMgr *connection.ConnectionManager, filename, resumePath string) (*storage.MultiSink, *history.Sink, error) {
+	if resumePath != "" {
+		filename = filepath.Base(resumePath)
+	}
+
+	connect := func(name string) (executor.QueryExecutor, string, error) {
+		for _, wh := range cfg.Warehouses {
+			if wh.Name == name {
+				exec, err := executor.CreateExecutor(wh, connMgr, cfg.Schema)
+				return exec, wh.Type, err
+			}
+		}
+
+		return nil, "", fmt.Errorf("хранилище %s не найдено", name)
+	}
+
+	var sinks []storage.ResultSink
+	resumed := false
+
+	for _, sc := range cfg.Sinks {
+		if sc.Type == "csv" && resumePath != "" && !resumed {
+			sinks = append(sinks, storage.ResumeCSVStorage(resumePath))
+			resumed = true
+			continue
+		}
+
+		sink, err := storage.CreateSink(sc, filename, connect)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if resumePath != "" && !resumed {
+		return nil, nil, fmt.Errorf("для продолжения запуска нужен sink типа csv")
+	}
+
+	var hist *history.Sink
+	if cfg.History != nil && cfg.History.Enabled {
+		snapshot, err := yaml.Marshal(cfg.Redacted())
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка сериализации конфигурации: %w", err)
+		}
+
+		hist = history.NewSink(cfg.History.Path, history.Run{
+			Name:        strings.TrimSuffix(filename, filepath.Ext(filename)),
+			StartedAt:   time.Now(),
+			Suite:       cfg.Suite,
+			GitRevision: utils.GitRevision(),
+			Config:      string(snapshot),
+		})
+		sinks = append(sinks, hist)
+	}
+
+	multi := storage.NewMultiSink(sinks...)
+	if err := multi.Open(); err != nil {
+		return nil, nil, err
+	}
+
+	return multi, hist, nil
+}
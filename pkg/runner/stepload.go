@@ -0,0 +1,284 @@
+package runner
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/stats"
+	"tpcds_benchmark/pkg/storage"
+)
+
+// stepStats - итог одного шага concurrency на хранилище
+type stepStats struct {
+	warehouse   string
+	concurrency int
+	start       time.Time
+	end         time.Time
+
+	executed  int
+	succeeded int
+	failed    int
+
+	// успешных запросов в минуту
+	throughput float64
+	errorRate  float64
+
+	// перцентили длительности успешных запросов, ms
+	p50 float64
+	p95 float64
+	p99 float64
+}
+
+func newStepStats(warehouse string, concurrency int, start, end time.Time, results []storage.BenchmarkResult) stepStats {
+	st := stepStats{
+		warehouse:   warehouse,
+		concurrency: concurrency,
+		start:       start,
+		end:         end,
+	}
+
+	var durations []float64
+
+	for _, r := range results {
+		switch r.Status {
+		case storage.StatusSuccess:
+			st.succeeded++
+			durations = append(durations, float64(r.DurationMs))
+		case storage.StatusError:
+			st.failed++
+		default:
+			continue
+		}
+
+		st.executed++
+	}
+
+	if elapsed := end.Sub(start); elapsed > 0 {
+		st.throughput = float64(st.succeeded) / elapsed.Minutes()
+	}
+
+	if st.executed > 0 {
+		st.errorRate = float64(st.failed) / float64(st.executed)
+	}
+
+	sorted := stats.Sorted(durations)
+	st.p50 = stats.Percentile(sorted, 50)
+	st.p95 = stats.Percentile(sorted, 95)
+	st.p99 = stats.Percentile(sorted, 99)
+
+	return st
+}
+
+// stepCurve - кривая throughput / latency от concurrency по всем хранилищам
+type stepCurve struct {
+	mu    sync.Mutex
+	steps []stepStats
+}
+
+func (c *stepCurve) add(st stepStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.steps = append(c.steps, st)
+}
+
+func (c *stepCurve) log() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, st := range c.steps {
+		log.Printf("кривая %s: concurrency %d: %.2f запросов/мин, p50 %.0f ms, p95 %.0f ms, p99 %.0f ms, ошибок %.1f%%",
+			st.warehouse,
+			st.concurrency,
+			st.throughput,
+			st.p50,
+			st.p95,
+			st.p99,
+			st.errorRate*100,
+		)
+	}
+}
+
+var stepCurveHeader = []string{
+	"warehouse",
+	"concurrency",
+	"start_timestamp",
+	"end_timestamp",
+	"executed",
+	"succeeded",
+	"failed",
+	"throughput_per_min",
+	"error_rate",
+	"p50_ms",
+	"p95_ms",
+	"p99_ms",
+}
+
+func (c *stepCurve) write(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	if err := writer.Write(stepCurveHeader); err != nil {
+		return err
+	}
+
+	for _, st := range c.steps {
+		record := []string{
+			st.warehouse,
+			strconv.Itoa(st.concurrency),
+			st.start.Format(time.RFC3339),
+			st.end.Format(time.RFC3339),
+			strconv.Itoa(st.executed),
+			strconv.Itoa(st.succeeded),
+			strconv.Itoa(st.failed),
+			formatFloat(st.throughput),
+			formatFloat(st.errorRate),
+			formatFloat(st.p50),
+			formatFloat(st.p95),
+			formatFloat(st.p99),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// formatFloat - пустая строка вместо NaN (на шаге не было успешных запросов)
+func formatFloat(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// runStepLoad выполняет набор запросов на каждом шаге concurrency и останавливается,
+// когда p95 или доля ошибок шага превышают порог
+func (br *BenchmarkRunner) runStepLoad(session *warehouseSession) {
+	cfg := br.cfg.StepLoad
+	wh := session.wh
+
+	stepDuration := parseOptionalDuration(cfg.StepDuration)
+	maxP95 := parseOptionalDuration(cfg.MaxP95)
+
+	session.totalTasks = br.plannedTasks()
+
+	log.Printf("[%s] step-load: шаги %v, запросов: %d", wh.Name, cfg.Steps, len(br.queries))
+
+	writer := br.newResultWriter()
+
+	executed := 0
+
+	for _, step := range cfg.Steps {
+		if session.stopped() {
+			log.Printf("[%s] step-load: бюджет времени исчерпан перед шагом %d", wh.Name, step)
+			break
+		}
+
+		session.concurrency = step
+
+		start := time.Now()
+		results := br.runStep(session, writer, step, stepDuration)
+		st := newStepStats(wh.Name, step, start, time.Now(), results)
+
+		executed += len(results)
+		br.curve.add(st)
+
+		log.Printf("[%s] шаг concurrency %d: %.2f запросов/мин, p95 %.0f ms, ошибок %.1f%%",
+			wh.Name,
+			step,
+			st.throughput,
+			st.p95,
+			st.errorRate*100,
+		)
+
+		if maxP95 > 0 && st.p95 > float64(maxP95.Milliseconds()) {
+			log.Printf("[%s] step-load остановлен: p95 %.0f ms больше порога %s", wh.Name, st.p95, cfg.MaxP95)
+			break
+		}
+
+		if cfg.MaxErrorRate > 0 && st.errorRate > cfg.MaxErrorRate {
+			log.Printf("[%s] step-load остановлен: доля ошибок %.1f%% больше порога %.1f%%",
+				wh.Name,
+				st.errorRate*100,
+				cfg.MaxErrorRate*100,
+			)
+			break
+		}
+	}
+
+	writer.close()
+
+	// шаги после точки насыщения не планировались к выполнению
+	session.mu.Lock()
+	session.totalTasks = executed
+	session.mu.Unlock()
+}
+
+// runStep - step потоков выполняют все запросы step_rounds раз или до конца step_duration
+func (br *BenchmarkRunner) runStep(session *warehouseSession, writer *resultWriter, step int, stepDuration time.Duration) []storage.BenchmarkResult {
+	var deadline time.Time
+	if stepDuration > 0 {
+		deadline = time.Now().Add(stepDuration)
+	}
+
+	var (
+		mu      sync.Mutex
+		results []storage.BenchmarkResult
+		wg      sync.WaitGroup
+	)
+
+	for threadID := 0; threadID < step; threadID++ {
+		wg.Add(1)
+
+		go func(threadID int) {
+			defer wg.Done()
+
+			for round := 1; ; round++ {
+				if deadline.IsZero() && round > br.cfg.StepLoad.StepRounds {
+					return
+				}
+
+				for _, q := range br.queries {
+					if !deadline.IsZero() && (!time.Now().Before(deadline) || session.stopped()) {
+						return
+					}
+
+					result, ok := br.runTask(session, writer, task{query: q, run: round, threadID: threadID})
+					if !ok {
+						continue
+					}
+
+					mu.Lock()
+					results = append(results, result)
+					mu.Unlock()
+				}
+			}
+		}(threadID)
+	}
+
+	wg.Wait()
+
+	return results
+}
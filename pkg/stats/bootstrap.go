@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+)
+
+// MedianChangeCI - доверительный интервал относительного изменения медианы
+// (median(candidate)/median(baseline) - 1) перцентильным бутстрепом
+func MedianChangeCI(baseline, candidate []float64, iterations int, confidence float64, rnd *rand.Rand) (lo, hi float64) {
+	if len(baseline) == 0 || len(candidate) == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	changes := make([]float64, 0, iterations)
+	bs := make([]float64, len(baseline))
+	cs := make([]float64, len(candidate))
+
+	for i := 0; i < iterations; i++ {
+		resample(bs, baseline, rnd)
+		resample(cs, candidate, rnd)
+
+		base := Percentile(Sorted(bs), 50)
+		if base <= 0 {
+			continue
+		}
+
+		changes = append(changes, Percentile(Sorted(cs), 50)/base-1)
+	}
+
+	if len(changes) == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	sorted := Sorted(changes)
+	tail := (1 - confidence) / 2 * 100
+
+	return Percentile(sorted, tail), Percentile(sorted, 100-tail)
+}
+
+func resample(dst, src []float64, rnd *rand.Rand) {
+	for i := range dst {
+		dst[i] = src[rnd.Intn(len(src))]
+	}
+}
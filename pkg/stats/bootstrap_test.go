@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMedianChangeCIConstant(t *testing.T) {
+	base := []float64{100, 100, 100}
+	cand := []float64{150, 150, 150, 150}
+
+	lo, hi := MedianChangeCI(base, cand, 500, 0.95, rand.New(rand.NewSource(1)))
+	if !near(lo, 0.5) || !near(hi, 0.5) {
+		t.Errorf("constant samples: got [%v, %v], want [0.5, 0.5]", lo, hi)
+	}
+}
+
+func TestMedianChangeCIContainsPointEstimate(t *testing.T) {
+	base := []float64{98, 99, 100, 101, 102, 103, 97}
+	cand := []float64{148, 149, 150, 151, 152, 153, 147}
+
+	lo, hi := MedianChangeCI(base, cand, 2000, 0.95, rand.New(rand.NewSource(1)))
+
+	point := Percentile(Sorted(cand), 50)/Percentile(Sorted(base), 50) - 1
+	if !(lo <= point && point <= hi) {
+		t.Errorf("point %v outside [%v, %v]", point, lo, hi)
+	}
+
+	// выборки не пересекаются: интервал целиком выше нуля
+	if lo <= 0 || hi >= 1 {
+		t.Errorf("interval [%v, %v] too wide", lo, hi)
+	}
+
+	// тот же seed - тот же интервал
+	lo2, hi2 := MedianChangeCI(base, cand, 2000, 0.95, rand.New(rand.NewSource(1)))
+	if lo != lo2 || hi != hi2 {
+		t.Errorf("not deterministic: [%v, %v] vs [%v, %v]", lo, hi, lo2, hi2)
+	}
+}
+
+func TestMedianChangeCIWiderAtHigherConfidence(t *testing.T) {
+	base := []float64{50, 80, 100, 120, 160}
+	cand := []float64{60, 90, 110, 140, 170}
+
+	lo90, hi90 := MedianChangeCI(base, cand, 2000, 0.90, rand.New(rand.NewSource(7)))
+	lo99, hi99 := MedianChangeCI(base, cand, 2000, 0.99, rand.New(rand.NewSource(7)))
+
+	if lo99 > lo90 || hi99 < hi90 {
+		t.Errorf("99%% [%v, %v] narrower than 90%% [%v, %v]", lo99, hi99, lo90, hi90)
+	}
+}
+
+func TestMedianChangeCIUndefined(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for name, pair := range map[string][2][]float64{
+		"empty baseline":  {nil, {1, 2}},
+		"empty candidate": {{1, 2}, nil},
+		"zero baseline":   {{0, 0}, {1, 2}},
+	} {
+		lo, hi := MedianChangeCI(pair[0], pair[1], 100, 0.95, rnd)
+		if !math.IsNaN(lo) || !math.IsNaN(hi) {
+			t.Errorf("%s: got [%v, %v], want NaN", name, lo, hi)
+		}
+	}
+}
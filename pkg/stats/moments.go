@@ -0,0 +1,52 @@
+package stats
+
+import "math"
+
+// Mean - среднее арифметическое, NaN для пустого набора
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum / float64(len(values))
+}
+
+// StdDev - выборочное стандартное отклонение (n-1), 0 для одного значения
+func StdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	if len(values) == 1 {
+		return 0
+	}
+
+	mean := Mean(values)
+
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// Geomean - среднее геометрическое; значения меньше 1 считаются за 1,
+// чтобы запросы с нулевой длительностью не обнуляли результат
+func Geomean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += math.Log(math.Max(v, 1))
+	}
+
+	return math.Exp(sum / float64(len(values)))
+}
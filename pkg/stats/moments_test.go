@@ -0,0 +1,37 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMoments(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     func([]float64) float64
+		values []float64
+		want   float64
+	}{
+		{"mean", Mean, []float64{1, 2, 3, 4}, 2.5},
+		{"mean empty", Mean, nil, math.NaN()},
+
+		// сумма квадратов отклонений от 5 равна 32, делится на n-1 = 7
+		{"stddev", StdDev, []float64{2, 4, 4, 4, 5, 5, 7, 9}, math.Sqrt(32.0 / 7)},
+		{"stddev single", StdDev, []float64{42}, 0},
+		{"stddev equal", StdDev, []float64{3, 3, 3}, 0},
+		{"stddev empty", StdDev, nil, math.NaN()},
+
+		{"geomean", Geomean, []float64{1, 10, 100}, 10},
+		{"geomean zero counts as one", Geomean, []float64{0, 100}, 10},
+		{"geomean below one", Geomean, []float64{0.5, 0.1}, 1},
+		{"geomean empty", Geomean, nil, math.NaN()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.values); !near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Sorted возвращает отсортированную копию значений
+func Sorted(values []float64) []float64 {
+	out := append([]float64(nil), values...)
+	sort.Float64s(out)
+	return out
+}
+
+// Percentile - перцентиль p (0..100) по отсортированным значениям с линейной интерполяцией
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+
+	if len(sorted) == 1 || p <= 0 {
+		return sorted[0]
+	}
+
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	frac := rank - float64(lower)
+
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
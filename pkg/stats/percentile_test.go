@@ -0,0 +1,50 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// near сравнивает с точностью до 1e-9; NaN равен только NaN
+func near(got, want float64) bool {
+	if math.IsNaN(want) {
+		return math.IsNaN(got)
+	}
+
+	return math.Abs(got-want) < 1e-9
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{40, 10, 30, 20}
+	sorted := Sorted(values)
+
+	if values[0] != 40 {
+		t.Fatalf("Sorted modified its input: %v", values)
+	}
+
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"min", sorted, 0, 10},
+		{"below zero", sorted, -5, 10},
+		{"p25", sorted, 25, 17.5},
+		{"median even", sorted, 50, 25},
+		{"p90", sorted, 90, 37},
+		{"max", sorted, 100, 40},
+		{"above max", sorted, 120, 40},
+		{"median odd", []float64{1, 2, 9}, 50, 2},
+		{"single", []float64{5}, 99, 5},
+		{"empty", nil, 50, math.NaN()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percentile(tt.sorted, tt.p); !near(got, tt.want) {
+				t.Errorf("Percentile(%v, %v) = %v, want %v", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadCSVResults читает файл результатов CSVStorage, колонки сопоставляются по заголовку
+func ReadCSVResults(path string) ([]BenchmarkResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла результатов: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения заголовка %s: %w", path, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	var results []BenchmarkResult
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: строка %d: %w", path, line, err)
+		}
+
+		row := &csvRow{columns: columns, record: record}
+
+		result := BenchmarkResult{
+			SaveResultTimestamp: row.time("save_result_timestamp"),
+			StartTimestamp:      row.time("start_timestamp"),
+			EndTimestamp:        row.time("end_timestamp"),
+			QueryID:             row.str("query_id"),
+			Warehouse:           row.str("warehouse"),
+			Schema:              row.str("schema"),
+			RunNumber:           row.int("run_number"),
+			ThreadID:            row.int("thread_id"),
+			DurationMs:          row.int("duration_ms"),
+			Status:              row.str("status"),
+			ErrorMsg:            row.str("error_message"),
+			RowCount:            row.int("row_count"),
+			Reconnected:         row.bool("reconnected"),
+			Attempt:             row.int("attempt"),
+			ErrorClass:          row.str("error_class"),
+			ScheduledTimestamp:  row.time("scheduled_timestamp"),
+			QueueDelayMs:        row.int("queue_delay_ms"),
+			Concurrency:         row.int("concurrency"),
+			QueryClass:          row.str("query_class"),
+			Suite:               row.str("suite"),
+			PartDurationsMs:     row.ints("part_durations_ms"),
+			PartRowCounts:       row.ints("part_row_counts"),
+		}
+
+		if row.err != nil {
+			return nil, fmt.Errorf("%s: строка %d: %w", path, line, row.err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// csvRow - запись с доступом по имени колонки; пустое значение - нулевое,
+// первая ошибка разбора непустого значения сохраняется в err
+type csvRow struct {
+	columns map[string]int
+	record  []string
+	err     error
+}
+
+func (r *csvRow) str(name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+
+	return r.record[i]
+}
+
+func (r *csvRow) fail(name string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("колонка %s: %w", name, err)
+	}
+}
+
+func (r *csvRow) int(name string) int {
+	value := r.str(name)
+	if value == "" {
+		return 0
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		r.fail(name, err)
+	}
+
+	return v
+}
+
+func (r *csvRow) ints(name string) []int {
+	value := r.str(name)
+	if value == "" {
+		return nil
+	}
+
+	var values []int
+	for _, part := range strings.Split(value, ";") {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			r.fail(name, err)
+		}
+		values = append(values, v)
+	}
+
+	return values
+}
+
+func (r *csvRow) bool(name string) bool {
+	value := r.str(name)
+	if value == "" {
+		return false
+	}
+
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		r.fail(name, err)
+	}
+
+	return v
+}
+
+func (r *csvRow) time(name string) time.Time {
+	value := r.str(name)
+	if value == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		r.fail(name, err)
+	}
+
+	return t
+}
@@ -1,14 +1,27 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	StatusSuccess            = "success"
+	StatusError              = "error"
+	StatusSkippedCircuitOpen = "skipped_circuit_open"
+	StatusNotRun             = "not_run"
+)
+
 type BenchmarkResult struct {
 	SaveResultTimestamp time.Time
 	StartTimestamp      time.Time
@@ -22,64 +35,181 @@ type BenchmarkResult struct {
 	Status              string
 	ErrorMsg            string
 	RowCount            int
+	Reconnected         bool
+	Attempt             int
+	ErrorClass          string
+
+	// open-loop: плановое время поступления и задержка в очереди до старта
+	ScheduledTimestamp time.Time
+	QueueDelayMs       int
+
+	// число потоков, с которым выполнялся запрос (шаг в step-load)
+	Concurrency int
+
+	// класс запросов сценария смешанной нагрузки
+	QueryClass string
+
+	// именованный набор запросов (suite), пусто для queries_path
+	Suite string
+
+	// многочастный запрос: длительность и строки каждой инструкции
+	PartDurationsMs []int
+	PartRowCounts   []int
+
+	// статистика движка, в csv не пишется
+	EngineMetrics map[string]any
+	PartMetrics   []map[string]any
 }
 
+// CSVStorage - получатель результатов в csv файл
 type CSVStorage struct {
 	filepath string
+	resume   bool
 	mu       sync.Mutex
 	writer   *csv.Writer
 	file     *os.File
 }
 
-func NewCSVStorage(dirpath, filename string) (*CSVStorage, error) {
-	if err := os.MkdirAll(dirpath, 0755); err != nil {
-		return nil, fmt.Errorf("ошибка при создании директории: %w", err)
+// NewCSVStorage - новый файл filename в dirpath, создается в Open
+func NewCSVStorage(dirpath, filename string) *CSVStorage {
+	return &CSVStorage{
+		filepath: filepath.Join(dirpath, filename),
+	}
+}
+
+// ResumeCSVStorage - существующий файл результатов, Open открывает его на дозапись (--resume)
+func ResumeCSVStorage(path string) *CSVStorage {
+	return &CSVStorage{
+		filepath: path,
+		resume:   true,
 	}
+}
 
-	fullpath := filepath.Join(dirpath, filename)
+func (s *CSVStorage) Open() error {
+	if s.resume {
+		return s.openExisting()
+	}
 
-	file, err := os.OpenFile(fullpath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err := os.MkdirAll(filepath.Dir(s.filepath), 0755); err != nil {
+		return fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	file, err := os.OpenFile(s.filepath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при создании файла: %w", err)
+		return fmt.Errorf("ошибка при создании файла: %w", err)
 	}
 
-	writer := csv.NewWriter(file)
+	s.file = file
+	s.writer = csv.NewWriter(file)
 
-	storage := &CSVStorage{
-		filepath: fullpath,
-		writer:   writer,
-		file:     file,
+	if err := s.writeHeader(); err != nil {
+		file.Close()
+		return err
 	}
 
-	if err := storage.writeHeader(); err != nil {
+	return nil
+}
+
+func (s *CSVStorage) openExisting() error {
+	file, err := os.OpenFile(s.filepath, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла: %w", err)
+	}
+
+	if err := prepareResume(file); err != nil {
 		file.Close()
-		return nil, err
+		return err
+	}
+
+	s.file = file
+	s.writer = csv.NewWriter(file)
+
+	return nil
+}
+
+// PrepareResumeCSV готовит файл результатов к продолжению: отрезает недописанную
+// строку и проверяет, что заголовок совпадает с текущим форматом
+func PrepareResumeCSV(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла: %w", err)
+	}
+	defer file.Close()
+
+	return prepareResume(file)
+}
+
+func prepareResume(file *os.File) error {
+	if err := truncatePartialRecord(file); err != nil {
+		return err
+	}
+
+	header, err := csv.NewReader(file).Read()
+	if err != nil {
+		return fmt.Errorf("ошибка чтения заголовка: %w", err)
+	}
+
+	if !slices.Equal(header, csvHeader) {
+		return fmt.Errorf("заголовок %s не совпадает с текущим форматом результатов", file.Name())
+	}
+
+	return nil
+}
+
+// truncatePartialRecord отрезает недописанную строку, если процесс упал во время записи
+func truncatePartialRecord(file *os.File) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла: %w", err)
 	}
 
-	return storage, nil
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if end == len(data) {
+		_, err = file.Seek(0, io.SeekStart)
+		return err
+	}
+
+	log.Printf("WARNING: %s: отброшена недописанная строка (%d байт)", file.Name(), len(data)-end)
+
+	if err := file.Truncate(int64(end)); err != nil {
+		return fmt.Errorf("ошибка обрезки файла: %w", err)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	return err
 }
 
 func (s *CSVStorage) GetFilePath() string {
 	return s.filepath
 }
 
+var csvHeader = []string{
+	"save_result_timestamp",
+	"start_timestamp",
+	"end_timestamp",
+	"query_id",
+	"warehouse",
+	"schema",
+	"run_number",
+	"thread_id",
+	"duration_ms",
+	"status",
+	"error_message",
+	"row_count",
+	"reconnected",
+	"attempt",
+	"error_class",
+	"scheduled_timestamp",
+	"queue_delay_ms",
+	"concurrency",
+	"query_class",
+	"suite",
+	"part_durations_ms",
+	"part_row_counts",
+}
+
 func (s *CSVStorage) writeHeader() error {
-	header := []string{
-		"save_result_timestamp",
-		"start_timestamp",
-		"end_timestamp",
-		"query_id",
-		"warehouse",
-		"schema",
-		"run_number",
-		"thread_id",
-		"duration_ms",
-		"status",
-		"error_message",
-		"row_count",
-	}
-
-	if err := s.writer.Write(header); err != nil {
+	if err := s.writer.Write(csvHeader); err != nil {
 		return err
 	}
 
@@ -92,9 +222,9 @@ func (s *CSVStorage) Save(result BenchmarkResult) error {
 	defer s.mu.Unlock()
 
 	record := []string{
-		result.SaveResultTimestamp.Format(time.RFC3339),
-		result.StartTimestamp.Format(time.RFC3339),
-		result.EndTimestamp.Format(time.RFC3339),
+		result.SaveResultTimestamp.Format(time.RFC3339Nano),
+		result.StartTimestamp.Format(time.RFC3339Nano),
+		result.EndTimestamp.Format(time.RFC3339Nano),
 		result.QueryID,
 		result.Warehouse,
 		result.Schema,
@@ -104,20 +234,59 @@ func (s *CSVStorage) Save(result BenchmarkResult) error {
 		result.Status,
 		result.ErrorMsg,
 		fmt.Sprintf("%d", result.RowCount),
+		strconv.FormatBool(result.Reconnected),
+		strconv.Itoa(result.Attempt),
+		result.ErrorClass,
+		formatOptionalTime(result.ScheduledTimestamp),
+		strconv.Itoa(result.QueueDelayMs),
+		strconv.Itoa(result.Concurrency),
+		result.QueryClass,
+		result.Suite,
+		formatInts(result.PartDurationsMs),
+		formatInts(result.PartRowCounts),
 	}
 
-	if err := s.writer.Write(record); err != nil {
-		return err
-	}
+	return s.writer.Write(record)
+}
+
+func (s *CSVStorage) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	s.writer.Flush()
 	return s.writer.Error()
 }
 
+func (s *CSVStorage) Artifacts() []Artifact {
+	return []Artifact{FileArtifact(s.filepath)}
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
+
+// formatInts - значения по частям запроса через ";"
+func formatInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+
+	return strings.Join(parts, ";")
+}
+
 func (s *CSVStorage) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.file == nil {
+		return nil
+	}
+
 	s.writer.Flush()
 	return s.file.Close()
 }
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCSVRoundTripKeepsSubSecondTimestamps(t *testing.T) {
+	dir := t.TempDir()
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	want := BenchmarkResult{
+		SaveResultTimestamp: start.Add(300 * time.Millisecond),
+		StartTimestamp:      start,
+		EndTimestamp:        start.Add(250 * time.Millisecond),
+		ScheduledTimestamp:  start.Add(-5 * time.Millisecond),
+		QueryID:             "q1",
+		Warehouse:           "trino",
+		RunNumber:           1,
+		DurationMs:          250,
+		Status:              StatusSuccess,
+		PartDurationsMs:     []int{100, 150},
+	}
+
+	s := NewCSVStorage(dir, "run.csv")
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ReadCSVResults(s.GetFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	got := results[0]
+	for name, pair := range map[string][2]time.Time{
+		"save_result_timestamp": {got.SaveResultTimestamp, want.SaveResultTimestamp},
+		"start_timestamp":       {got.StartTimestamp, want.StartTimestamp},
+		"end_timestamp":         {got.EndTimestamp, want.EndTimestamp},
+		"scheduled_timestamp":   {got.ScheduledTimestamp, want.ScheduledTimestamp},
+	} {
+		if !pair[0].Equal(pair[1]) {
+			t.Errorf("%s: got %s, want %s", name, pair[0].Format(time.RFC3339Nano), pair[1].Format(time.RFC3339Nano))
+		}
+	}
+
+	if got.EndTimestamp.Sub(got.StartTimestamp) != 250*time.Millisecond {
+		t.Errorf("wall time quantized: %v", got.EndTimestamp.Sub(got.StartTimestamp))
+	}
+}
+
+func TestReadCSVAcceptsWholeSecondTimestamps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.csv")
+
+	content := "save_result_timestamp,start_timestamp,end_timestamp,query_id,warehouse,status\n" +
+		"2026-01-02T03:04:06Z,2026-01-02T03:04:05Z,2026-01-02T03:04:06Z,q1,trino,success\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ReadCSVResults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].EndTimestamp.Sub(results[0].StartTimestamp) != time.Second {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSONLSchemaVersion увеличивается при несовместимом изменении формата записи
+const JSONLSchemaVersion = 1
+
+// JSONLStorage - получатель результатов в JSON Lines: одна самоописывающая запись на результат
+type JSONLStorage struct {
+	filepath string
+	runID    string
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func NewJSONLStorage(dirpath, filename string) *JSONLStorage {
+	return &JSONLStorage{
+		filepath: filepath.Join(dirpath, filename),
+		runID:    strings.TrimSuffix(filename, filepath.Ext(filename)),
+	}
+}
+
+type jsonlRecord struct {
+	SchemaVersion int `json:"schema_version"`
+
+	Run       jsonlRun       `json:"run"`
+	Query     jsonlQuery     `json:"query"`
+	Warehouse jsonlWarehouse `json:"warehouse"`
+	Execution jsonlExecution `json:"execution"`
+
+	Status string      `json:"status"`
+	Error  *jsonlError `json:"error,omitempty"`
+
+	// статистика движка как ее отдает драйвер, для многочастных запросов - по частям
+	Engine map[string]any `json:"engine,omitempty"`
+}
+
+type jsonlRun struct {
+	ID      string    `json:"id"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+type jsonlQuery struct {
+	ID    string `json:"id"`
+	Class string `json:"class,omitempty"`
+	Suite string `json:"suite,omitempty"`
+}
+
+type jsonlWarehouse struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+type jsonlExecution struct {
+	RunNumber   int  `json:"run_number"`
+	ThreadID    int  `json:"thread_id"`
+	Concurrency int  `json:"concurrency,omitempty"`
+	Attempt     int  `json:"attempt,omitempty"`
+	Reconnected bool `json:"reconnected"`
+
+	Start     *time.Time `json:"start,omitempty"`
+	End       *time.Time `json:"end,omitempty"`
+	Scheduled *time.Time `json:"scheduled,omitempty"`
+
+	QueueDelayMs int `json:"queue_delay_ms"`
+	DurationMs   int `json:"duration_ms"`
+	RowCount     int `json:"row_count"`
+
+	Parts []jsonlPart `json:"parts,omitempty"`
+}
+
+type jsonlPart struct {
+	DurationMs int            `json:"duration_ms"`
+	RowCount   int            `json:"row_count"`
+	Engine     map[string]any `json:"engine,omitempty"`
+}
+
+type jsonlError struct {
+	Class   string `json:"class,omitempty"`
+	Message string `json:"message"`
+}
+
+// Open создает файл или дописывает в существующий (--resume), отбрасывая недописанную строку
+func (s *JSONLStorage) Open() error {
+	if err := os.MkdirAll(filepath.Dir(s.filepath), 0755); err != nil {
+		return fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	file, err := os.OpenFile(s.filepath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %w", err)
+	}
+
+	if err := truncatePartialRecord(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+
+	return nil
+}
+
+func (s *JSONLStorage) Save(result BenchmarkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(s.record(result))
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации результата: %w", err)
+	}
+
+	if _, err := s.writer.Write(data); err != nil {
+		return err
+	}
+
+	return s.writer.WriteByte('\n')
+}
+
+func (s *JSONLStorage) record(result BenchmarkResult) jsonlRecord {
+	rec := jsonlRecord{
+		SchemaVersion: JSONLSchemaVersion,
+		Run: jsonlRun{
+			ID:      s.runID,
+			SavedAt: result.SaveResultTimestamp,
+		},
+		Query: jsonlQuery{
+			ID:    result.QueryID,
+			Class: result.QueryClass,
+			Suite: result.Suite,
+		},
+		Warehouse: jsonlWarehouse{
+			Name:   result.Warehouse,
+			Schema: result.Schema,
+		},
+		Execution: jsonlExecution{
+			RunNumber:    result.RunNumber,
+			ThreadID:     result.ThreadID,
+			Concurrency:  result.Concurrency,
+			Attempt:      result.Attempt,
+			Reconnected:  result.Reconnected,
+			Start:        optionalTime(result.StartTimestamp),
+			End:          optionalTime(result.EndTimestamp),
+			Scheduled:    optionalTime(result.ScheduledTimestamp),
+			QueueDelayMs: result.QueueDelayMs,
+			DurationMs:   result.DurationMs,
+			RowCount:     result.RowCount,
+		},
+		Status: result.Status,
+		Engine: result.EngineMetrics,
+	}
+
+	for i, d := range result.PartDurationsMs {
+		part := jsonlPart{DurationMs: d}
+		if i < len(result.PartRowCounts) {
+			part.RowCount = result.PartRowCounts[i]
+		}
+		if i < len(result.PartMetrics) {
+			part.Engine = result.PartMetrics[i]
+		}
+
+		rec.Execution.Parts = append(rec.Execution.Parts, part)
+	}
+
+	if result.ErrorMsg != "" || result.ErrorClass != "" {
+		rec.Error = &jsonlError{
+			Class:   result.ErrorClass,
+			Message: result.ErrorMsg,
+		}
+	}
+
+	return rec
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func (s *JSONLStorage) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.writer.Flush()
+}
+
+func (s *JSONLStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	if err := s.writer.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+
+	return s.file.Close()
+}
+
+func (s *JSONLStorage) Artifacts() []Artifact {
+	return []Artifact{FileArtifact(s.filepath)}
+}
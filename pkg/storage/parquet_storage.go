@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ParquetStorage - получатель результатов в parquet для внешней таблицы в озере.
+// Parquet нельзя дописывать, поэтому строки копятся в памяти и пишутся частями
+// <runID>-<время Open>-<номер>.parquet в партиции run_date=/warehouse=/: на Flush,
+// когда накопилось parquetPartRows строк, и на Close. Продолжение запуска с тем же
+// runID добавляет новые части, а не перезаписывает старые
+type ParquetStorage struct {
+	dir     string
+	runID   string
+	runDate string
+
+	// время Open в имени частей, чтобы части разных запусков не совпадали в s3
+	opened string
+
+	mu      sync.Mutex
+	rows    map[string][]parquetRow
+	pending int
+	part    int
+	files   []Artifact
+}
+
+// parquetPartRows - сколько строк копится в памяти до записи очередной части
+const parquetPartRows = 1000
+
+type parquetRow struct {
+	RunID string `parquet:"run_id"`
+
+	SaveResultTimestamp time.Time  `parquet:"save_result_timestamp,timestamp(millisecond)"`
+	StartTimestamp      *time.Time `parquet:"start_timestamp,optional,timestamp(millisecond)"`
+	EndTimestamp        *time.Time `parquet:"end_timestamp,optional,timestamp(millisecond)"`
+	ScheduledTimestamp  *time.Time `parquet:"scheduled_timestamp,optional,timestamp(millisecond)"`
+
+	QueryID     string `parquet:"query_id"`
+	QueryClass  string `parquet:"query_class,optional"`
+	Suite       string `parquet:"suite,optional"`
+	Warehouse   string `parquet:"warehouse"`
+	Schema      string `parquet:"schema"`
+	RunNumber   int32  `parquet:"run_number"`
+	ThreadID    int32  `parquet:"thread_id"`
+	Concurrency int32  `parquet:"concurrency"`
+	Attempt     int32  `parquet:"attempt"`
+	Reconnected bool   `parquet:"reconnected"`
+
+	Status       string `parquet:"status"`
+	ErrorClass   string `parquet:"error_class,optional"`
+	ErrorMessage string `parquet:"error_message,optional"`
+
+	DurationMs   int64 `parquet:"duration_ms"`
+	QueueDelayMs int64 `parquet:"queue_delay_ms"`
+	RowCount     int64 `parquet:"row_count"`
+
+	PartDurationsMs []int64 `parquet:"part_durations_ms,list"`
+	PartRowCounts   []int64 `parquet:"part_row_counts,list"`
+
+	// статистика движка, null если движок ее не отдает
+	CPUTimeMs       *int64 `parquet:"cpu_time_ms,optional"`
+	QueuedTimeMs    *int64 `parquet:"queued_time_ms,optional"`
+	ProcessedRows   *int64 `parquet:"processed_rows,optional"`
+	ProcessedBytes  *int64 `parquet:"processed_bytes,optional"`
+	PeakMemoryBytes *int64 `parquet:"peak_memory_bytes,optional"`
+	SpilledBytes    *int64 `parquet:"spilled_bytes,optional"`
+
+	// false - итоговая статистика не дошла от драйвера, значения промежуточные
+	StatsFinal *bool `parquet:"stats_final,optional"`
+}
+
+func NewParquetStorage(dirpath, filename string) *ParquetStorage {
+	return &ParquetStorage{
+		dir:   dirpath,
+		runID: strings.TrimSuffix(filename, filepath.Ext(filename)),
+		rows:  make(map[string][]parquetRow),
+	}
+}
+
+func (s *ParquetStorage) Open() error {
+	now := time.Now().UTC()
+	s.runDate = now.Format(time.DateOnly)
+	s.opened = now.Format("20060102T150405Z")
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ParquetStorage) Save(result BenchmarkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row := parquetRow{
+		RunID:               s.runID,
+		SaveResultTimestamp: result.SaveResultTimestamp,
+		StartTimestamp:      optionalTime(result.StartTimestamp),
+		EndTimestamp:        optionalTime(result.EndTimestamp),
+		ScheduledTimestamp:  optionalTime(result.ScheduledTimestamp),
+		QueryID:             result.QueryID,
+		QueryClass:          result.QueryClass,
+		Suite:               result.Suite,
+		Warehouse:           result.Warehouse,
+		Schema:              result.Schema,
+		RunNumber:           int32(result.RunNumber),
+		ThreadID:            int32(result.ThreadID),
+		Concurrency:         int32(result.Concurrency),
+		Attempt:             int32(result.Attempt),
+		Reconnected:         result.Reconnected,
+		Status:              result.Status,
+		ErrorClass:          result.ErrorClass,
+		ErrorMessage:        result.ErrorMsg,
+		DurationMs:          int64(result.DurationMs),
+		QueueDelayMs:        int64(result.QueueDelayMs),
+		RowCount:            int64(result.RowCount),
+		PartDurationsMs:     toInt64s(result.PartDurationsMs),
+		PartRowCounts:       toInt64s(result.PartRowCounts),
+		CPUTimeMs:           metricInt64(result.EngineMetrics, "cpu_time_ms"),
+		QueuedTimeMs:        metricInt64(result.EngineMetrics, "queued_time_ms"),
+		ProcessedRows:       metricInt64(result.EngineMetrics, "processed_rows"),
+		ProcessedBytes:      metricInt64(result.EngineMetrics, "processed_bytes"),
+		PeakMemoryBytes:     metricInt64(result.EngineMetrics, "peak_memory_bytes"),
+		SpilledBytes:        metricInt64(result.EngineMetrics, "spilled_bytes"),
+		StatsFinal:          metricBool(result.EngineMetrics, "stats_final"),
+	}
+
+	s.rows[result.Warehouse] = append(s.rows[result.Warehouse], row)
+	s.pending++
+
+	return nil
+}
+
+// Flush пишет накопленные строки отдельной частью, если их достаточно,
+// чтобы при падении терялось не больше parquetPartRows строк
+func (s *ParquetStorage) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending < parquetPartRows {
+		return nil
+	}
+
+	return s.writePart()
+}
+
+func (s *ParquetStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rows == nil {
+		return nil
+	}
+
+	if err := s.writePart(); err != nil {
+		return err
+	}
+
+	s.rows = nil
+
+	return nil
+}
+
+// writePart пишет накопленные строки каждого хранилища в новый файл его партиции
+func (s *ParquetStorage) writePart() error {
+	if s.pending == 0 {
+		return nil
+	}
+
+	warehouses := make([]string, 0, len(s.rows))
+	for wh := range s.rows {
+		warehouses = append(warehouses, wh)
+	}
+	sort.Strings(warehouses)
+
+	s.part++
+
+	for _, wh := range warehouses {
+		dir := path.Join("run_date="+s.runDate, "warehouse="+partitionValue(wh))
+
+		local, key, err := s.partPath(dir)
+		if err != nil {
+			return err
+		}
+
+		if err := parquet.WriteFile(local, s.rows[wh]); err != nil {
+			return fmt.Errorf("ошибка записи parquet %s: %w", local, err)
+		}
+
+		s.files = append(s.files, Artifact{Path: local, Key: key})
+		delete(s.rows, wh)
+	}
+
+	s.pending = 0
+
+	return nil
+}
+
+// partPath - путь новой части; существующие файлы не перезаписываются,
+// даже если продолжение запуска открыто в ту же секунду
+func (s *ParquetStorage) partPath(dir string) (string, string, error) {
+	localDir := filepath.Join(s.dir, filepath.FromSlash(dir))
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return "", "", fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	for ; ; s.part++ {
+		name := fmt.Sprintf("%s-%s-%03d.parquet", s.runID, s.opened, s.part)
+		local := filepath.Join(localDir, name)
+
+		if _, err := os.Stat(local); os.IsNotExist(err) {
+			return local, path.Join(dir, name), nil
+		}
+	}
+}
+
+func (s *ParquetStorage) Artifacts() []Artifact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.files
+}
+
+// partitionValue - имя хранилища, безопасное для пути партиции
+func partitionValue(value string) string {
+	return strings.NewReplacer("/", "_", "=", "_", " ", "_").Replace(value)
+}
+
+func toInt64s(values []int) []int64 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	out := make([]int64, len(values))
+	for i, v := range values {
+		out[i] = int64(v)
+	}
+
+	return out
+}
+
+func metricInt64(metrics map[string]any, name string) *int64 {
+	var v int64
+
+	switch value := metrics[name].(type) {
+	case int:
+		v = int64(value)
+	case int64:
+		v = value
+	case float64:
+		v = int64(value)
+	default:
+		return nil
+	}
+
+	return &v
+}
+
+func metricBool(metrics map[string]any, name string) *bool {
+	v, ok := metrics[name].(bool)
+	if !ok {
+		return nil
+	}
+
+	return &v
+}
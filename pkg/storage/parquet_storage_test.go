@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func saveParquet(t *testing.T, s *ParquetStorage, warehouse string, runs ...int) {
+	t.Helper()
+
+	for _, run := range runs {
+		err := s.Save(BenchmarkResult{
+			SaveResultTimestamp: time.Now(),
+			QueryID:             "q1",
+			Warehouse:           warehouse,
+			RunNumber:           run,
+			Status:              StatusSuccess,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readParquetParts(t *testing.T, pattern string) ([]string, []int) {
+	t.Helper()
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var runs []int
+	for _, file := range files {
+		results, err := ReadParquetResults(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, r := range results {
+			runs = append(runs, r.RunNumber)
+		}
+	}
+
+	sort.Ints(runs)
+	return files, runs
+}
+
+func TestParquetResumeKeepsEarlierParts(t *testing.T) {
+	dir := t.TempDir()
+
+	first := NewParquetStorage(dir, "run.csv")
+	if err := first.Open(); err != nil {
+		t.Fatal(err)
+	}
+	saveParquet(t, first, "trino", 1, 2)
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// продолжение запуска с тем же runID, в ту же секунду
+	resumed := NewParquetStorage(dir, "run.csv")
+	if err := resumed.Open(); err != nil {
+		t.Fatal(err)
+	}
+	saveParquet(t, resumed, "trino", 3)
+	if err := resumed.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, runs := readParquetParts(t, filepath.Join(dir, "run_date=*", "warehouse=trino", "run-*.parquet"))
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 part files, got %v", files)
+	}
+
+	if len(runs) != 3 || runs[0] != 1 || runs[1] != 2 || runs[2] != 3 {
+		t.Errorf("expected runs [1 2 3], got %v", runs)
+	}
+
+	keys := map[string]bool{}
+	for _, a := range append(first.Artifacts(), resumed.Artifacts()...) {
+		if keys[a.Key] {
+			t.Errorf("duplicate s3 key %s", a.Key)
+		}
+		keys[a.Key] = true
+	}
+}
+
+func TestParquetFlushWritesPartBeforeClose(t *testing.T) {
+	dir := t.TempDir()
+
+	s := NewParquetStorage(dir, "run.csv")
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	saveParquet(t, s, "trino", 1)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := filepath.Join(dir, "run_date=*", "warehouse=*", "*.parquet")
+	if files, _ := readParquetParts(t, pattern); len(files) != 0 {
+		t.Fatalf("part written below threshold: %v", files)
+	}
+
+	runs := make([]int, parquetPartRows)
+	for i := range runs {
+		runs[i] = i + 2
+	}
+	saveParquet(t, s, "impala", runs...)
+
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// без Close: так остается на диске при падении процесса
+	files, written := readParquetParts(t, pattern)
+	if len(files) != 2 || len(written) != parquetPartRows+1 {
+		t.Fatalf("expected 2 parts with %d rows, got %v with %d rows", parquetPartRows+1, files, len(written))
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if files, _ := readParquetParts(t, pattern); len(files) != 2 {
+		t.Errorf("close with nothing pending wrote extra parts: %v", files)
+	}
+}
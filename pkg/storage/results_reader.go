@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ReadResults читает файл результатов любого файлового получателя по расширению
+func ReadResults(path string) ([]BenchmarkResult, error) {
+	switch filepath.Ext(path) {
+	case ".csv":
+		return ReadCSVResults(path)
+	case ".jsonl":
+		return ReadJSONLResults(path)
+	case ".parquet":
+		return ReadParquetResults(path)
+	default:
+		return nil, fmt.Errorf("неизвестный формат файла результатов: %s", path)
+	}
+}
+
+// ReadJSONLResults читает файл JSONLStorage; недописанная последняя строка пропускается
+func ReadJSONLResults(path string) ([]BenchmarkResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла результатов: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	var results []BenchmarkResult
+
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil {
+			// без перевода строки - запись не дописана
+			break
+		}
+
+		var rec jsonlRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("%s: строка %d: %w", path, line, err)
+		}
+
+		if rec.SchemaVersion > JSONLSchemaVersion {
+			return nil, fmt.Errorf("%s: строка %d: неподдерживаемая версия схемы %d", path, line, rec.SchemaVersion)
+		}
+
+		results = append(results, rec.result())
+	}
+
+	return results, nil
+}
+
+func (rec jsonlRecord) result() BenchmarkResult {
+	r := BenchmarkResult{
+		SaveResultTimestamp: rec.Run.SavedAt,
+		QueryID:             rec.Query.ID,
+		QueryClass:          rec.Query.Class,
+		Suite:               rec.Query.Suite,
+		Warehouse:           rec.Warehouse.Name,
+		Schema:              rec.Warehouse.Schema,
+		RunNumber:           rec.Execution.RunNumber,
+		ThreadID:            rec.Execution.ThreadID,
+		Concurrency:         rec.Execution.Concurrency,
+		Attempt:             rec.Execution.Attempt,
+		Reconnected:         rec.Execution.Reconnected,
+		QueueDelayMs:        rec.Execution.QueueDelayMs,
+		DurationMs:          rec.Execution.DurationMs,
+		RowCount:            rec.Execution.RowCount,
+		Status:              rec.Status,
+		EngineMetrics:       rec.Engine,
+	}
+
+	if rec.Execution.Start != nil {
+		r.StartTimestamp = *rec.Execution.Start
+	}
+	if rec.Execution.End != nil {
+		r.EndTimestamp = *rec.Execution.End
+	}
+	if rec.Execution.Scheduled != nil {
+		r.ScheduledTimestamp = *rec.Execution.Scheduled
+	}
+
+	if rec.Error != nil {
+		r.ErrorClass = rec.Error.Class
+		r.ErrorMsg = rec.Error.Message
+	}
+
+	for _, part := range rec.Execution.Parts {
+		r.PartDurationsMs = append(r.PartDurationsMs, part.DurationMs)
+		r.PartRowCounts = append(r.PartRowCounts, part.RowCount)
+		r.PartMetrics = append(r.PartMetrics, part.Engine)
+	}
+
+	return r
+}
+
+// ReadParquetResults читает один файл партиции ParquetStorage
+func ReadParquetResults(path string) ([]BenchmarkResult, error) {
+	rows, err := parquet.ReadFile[parquetRow](path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения parquet %s: %w", path, err)
+	}
+
+	results := make([]BenchmarkResult, len(rows))
+	for i, row := range rows {
+		results[i] = row.result()
+	}
+
+	return results, nil
+}
+
+func (row parquetRow) result() BenchmarkResult {
+	r := BenchmarkResult{
+		SaveResultTimestamp: row.SaveResultTimestamp,
+		QueryID:             row.QueryID,
+		QueryClass:          row.QueryClass,
+		Suite:               row.Suite,
+		Warehouse:           row.Warehouse,
+		Schema:              row.Schema,
+		RunNumber:           int(row.RunNumber),
+		ThreadID:            int(row.ThreadID),
+		Concurrency:         int(row.Concurrency),
+		Attempt:             int(row.Attempt),
+		Reconnected:         row.Reconnected,
+		Status:              row.Status,
+		ErrorClass:          row.ErrorClass,
+		ErrorMsg:            row.ErrorMessage,
+		DurationMs:          int(row.DurationMs),
+		QueueDelayMs:        int(row.QueueDelayMs),
+		RowCount:            int(row.RowCount),
+	}
+
+	if row.StartTimestamp != nil {
+		r.StartTimestamp = *row.StartTimestamp
+	}
+	if row.EndTimestamp != nil {
+		r.EndTimestamp = *row.EndTimestamp
+	}
+	if row.ScheduledTimestamp != nil {
+		r.ScheduledTimestamp = *row.ScheduledTimestamp
+	}
+
+	for _, d := range row.PartDurationsMs {
+		r.PartDurationsMs = append(r.PartDurationsMs, int(d))
+	}
+	for _, n := range row.PartRowCounts {
+		r.PartRowCounts = append(r.PartRowCounts, int(n))
+	}
+
+	return r
+}
+
+// resultKey - задача в одном файле результатов; concurrency различает шаги step-load,
+// в которых номера запусков и потоков повторяются
+type resultKey struct {
+	warehouse   string
+	queryID     string
+	run         int
+	thread      int
+	concurrency int
+}
+
+// LatestResults оставляет последнюю строку каждой задачи в порядке первого появления.
+// После -resume строки error и not_run остаются в файле, а повтор дописывается в конец,
+// поэтому отчеты по файлу и по запуску должны видеть только последнюю попытку.
+// Применяется к результатам одного запуска, а не к объединению нескольких файлов
+func LatestResults(results []BenchmarkResult) []BenchmarkResult {
+	index := make(map[resultKey]int, len(results))
+	latest := make([]BenchmarkResult, 0, len(results))
+
+	for _, r := range results {
+		key := resultKey{
+			warehouse:   r.Warehouse,
+			queryID:     r.QueryID,
+			run:         r.RunNumber,
+			thread:      r.ThreadID,
+			concurrency: r.Concurrency,
+		}
+
+		if i, ok := index[key]; ok {
+			latest[i] = r
+			continue
+		}
+
+		index[key] = len(latest)
+		latest = append(latest, r)
+	}
+
+	return latest
+}
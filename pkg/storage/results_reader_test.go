@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatestResultsAfterResume(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	row := func(queryID string, run int, status string) BenchmarkResult {
+		return BenchmarkResult{
+			SaveResultTimestamp: at,
+			QueryID:             queryID,
+			Warehouse:           "trino",
+			RunNumber:           run,
+			Concurrency:         1,
+			Status:              status,
+		}
+	}
+
+	// первая попытка: q1 с ошибкой, q2 не успел; продолжение дописывает повторы в конец
+	s := NewCSVStorage(dir, "run.csv")
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []BenchmarkResult{
+		row("q1", 1, StatusError),
+		row("q2", 1, StatusNotRun),
+		row("q3", 1, StatusSuccess),
+		row("q1", 1, StatusSuccess),
+		row("q2", 1, StatusSuccess),
+	} {
+		if err := s.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := ReadResults(s.GetFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest := LatestResults(raw)
+
+	if len(latest) != 3 {
+		t.Fatalf("expected 3 tasks, got %d: %+v", len(latest), latest)
+	}
+
+	for i, id := range []string{"q1", "q2", "q3"} {
+		if latest[i].QueryID != id || latest[i].Status != StatusSuccess {
+			t.Errorf("task %d: got %s %s, want %s success", i, latest[i].QueryID, latest[i].Status, id)
+		}
+	}
+}
+
+func TestLatestResultsKeepsDistinctTasks(t *testing.T) {
+	results := []BenchmarkResult{
+		{Warehouse: "trino", QueryID: "q1", RunNumber: 1, ThreadID: 0, Concurrency: 1, Status: StatusSuccess},
+		{Warehouse: "trino", QueryID: "q1", RunNumber: 2, ThreadID: 0, Concurrency: 1, Status: StatusSuccess},
+		{Warehouse: "trino", QueryID: "q1", RunNumber: 1, ThreadID: 1, Concurrency: 2, Status: StatusSuccess},
+		// шаг step-load с другим числом потоков повторяет номера запуска и потока
+		{Warehouse: "trino", QueryID: "q1", RunNumber: 1, ThreadID: 0, Concurrency: 2, Status: StatusError},
+		{Warehouse: "impala", QueryID: "q1", RunNumber: 1, ThreadID: 0, Concurrency: 1, Status: StatusSuccess},
+	}
+
+	if got := LatestResults(results); len(got) != len(results) {
+		t.Errorf("distinct tasks collapsed: %d of %d left", len(got), len(results))
+	}
+}
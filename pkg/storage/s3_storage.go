@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"mime"
 	"net/http"
 	"path/filepath"
 	"tpcds_benchmark/pkg/config"
@@ -53,14 +54,19 @@ func NewS3Storage(cfg *config.S3Config, certPath string) (*S3Storage, error) {
 }
 
 func (s *S3Storage) Upload(filePath string) error {
+	return s.UploadAs(filePath, filepath.Base(filePath))
+}
+
+// UploadAs загружает файл под ключом key относительно prefix (партиции run_date=/warehouse=/)
+func (s *S3Storage) UploadAs(filePath, key string) error {
 	ctx := context.Background()
 
 	info, err := s.client.FPutObject(
 		ctx,
 		s.bucket,
-		fmt.Sprintf("%s/%s", s.prefix, filepath.Base(filePath)),
+		fmt.Sprintf("%s/%s", s.prefix, key),
 		filePath, minio.PutObjectOptions{
-			ContentType: "text/csv",
+			ContentType: contentType(filePath),
 		},
 	)
 
@@ -72,3 +78,36 @@ func (s *S3Storage) Upload(filePath string) error {
 
 	return nil
 }
+
+func contentType(filePath string) string {
+	switch filepath.Ext(filePath) {
+	case ".csv":
+		return "text/csv"
+	case ".jsonl":
+		return "application/x-ndjson"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
+	}
+
+	if t := mime.TypeByExtension(filepath.Ext(filePath)); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
+
+// Download скачивает объект с ключом key относительно prefix в локальный файл
+func (s *S3Storage) Download(key, filePath string) error {
+	err := s.client.FGetObject(
+		context.Background(),
+		s.bucket,
+		fmt.Sprintf("%s/%s", s.prefix, key),
+		filePath,
+		minio.GetObjectOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки файла из s3 хранилища: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"tpcds_benchmark/pkg/config"
+)
+
+// ResultSink - получатель результатов бенчмарка. Save вызывается только из
+// одной горутины записи, Flush - когда очередь результатов опустела
+type ResultSink interface {
+	Open() error
+	Save(result BenchmarkResult) error
+	Flush() error
+	Close() error
+
+	// Artifacts - файлы, которые загружаются в s3 после Close
+	Artifacts() []Artifact
+}
+
+// Artifact - локальный файл результатов и его ключ в s3 относительно prefix
+type Artifact struct {
+	Path string
+	Key  string
+}
+
+// FileArtifact - файл кладется в s3 под своим именем
+func FileArtifact(path string) Artifact {
+	return Artifact{Path: path, Key: filepath.Base(path)}
+}
+
+// CreateSink создает получатель результатов по конфигурации;
+// filename - имя файла результатов запуска, расширение заменяется по типу,
+// connect нужен получателю table
+func CreateSink(cfg config.SinkConfig, filename string, connect WarehouseConnector) (ResultSink, error) {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	switch cfg.Type {
+	case "csv":
+		return NewCSVStorage(cfg.Path, base+".csv"), nil
+
+	case "jsonl":
+		return NewJSONLStorage(cfg.Path, base+".jsonl"), nil
+
+	case "parquet":
+		return NewParquetStorage(cfg.Path, base+".parquet"), nil
+
+	case "table":
+		return NewTableStorage(cfg, base, connect), nil
+
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип хранилища результатов: %s", cfg.Type)
+	}
+}
+
+// MultiSink раздает каждый результат всем получателям; вызовы сериализуются,
+// так как при parallel_warehouses у каждой группы свой writer
+type MultiSink struct {
+	sinks []ResultSink
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func NewMultiSink(sinks ...ResultSink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+func (m *MultiSink) Open() error {
+	for i, sink := range m.sinks {
+		if err := sink.Open(); err != nil {
+			for _, opened := range m.sinks[:i] {
+				opened.Close()
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MultiSink) Save(result BenchmarkResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+
+	for _, sink := range m.sinks {
+		if err := sink.Save(result); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MultiSink) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+
+	for _, sink := range m.sinks {
+		if err := sink.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close закрывает все получатели один раз, повторный вызов (по сигналу) ничего не делает
+func (m *MultiSink) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	var errs []error
+
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MultiSink) Artifacts() []Artifact {
+	var files []Artifact
+
+	for _, sink := range m.sinks {
+		files = append(files, sink.Artifacts()...)
+	}
+
+	return files
+}
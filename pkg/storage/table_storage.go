@@ -0,0 +1,436 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/executor"
+	"unicode/utf8"
+)
+
+// WarehouseConnector открывает соединение с хранилищем из конфигурации по имени
+// и возвращает тип движка
+type WarehouseConnector func(name string) (executor.QueryExecutor, string, error)
+
+// TableStorage - получатель результатов, который пачками вставляет строки
+// в таблицу на одном из хранилищ, чтобы дашборды читали данные во время запуска.
+// Вставки с повторами выполняет отдельная горутина, поэтому медленное хранилище
+// результатов не задерживает остальных получателей; неудачная пачка возвращается
+// в очередь и вставляется со следующей
+type TableStorage struct {
+	cfg     config.SinkConfig
+	connect WarehouseConnector
+	runID   string
+
+	flushInterval time.Duration
+	retryDelay    time.Duration
+	timeout       time.Duration
+
+	// соединение использует только горутина записи, а после ее остановки - Close
+	exec    executor.QueryExecutor
+	dialect tableDialect
+	schema  string
+
+	wake chan struct{}
+	done chan struct{}
+
+	mu        sync.Mutex
+	batch     []BenchmarkResult
+	lastFlush time.Time
+	closed    bool
+
+	// ошибка фоновой вставки, возвращается из следующего Flush
+	err error
+}
+
+func NewTableStorage(cfg config.SinkConfig, filename string, connect WarehouseConnector) *TableStorage {
+	flushInterval, _ := time.ParseDuration(cfg.FlushInterval)
+	retryDelay, _ := time.ParseDuration(cfg.RetryDelay)
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+
+	return &TableStorage{
+		cfg:     cfg,
+		connect: connect,
+		runID:   strings.TrimSuffix(filename, filepath.Ext(filename)),
+
+		flushInterval: flushInterval,
+		retryDelay:    retryDelay,
+		timeout:       timeout,
+	}
+}
+
+// tableColumn - колонка таблицы результатов и ее тип в общем виде
+type tableColumn struct {
+	name string
+	kind string // string, timestamp, int, bigint, bool
+}
+
+var tableColumns = []tableColumn{
+	{"run_id", "string"},
+	{"save_result_timestamp", "timestamp"},
+	{"start_timestamp", "timestamp"},
+	{"end_timestamp", "timestamp"},
+	{"query_id", "string"},
+	{"query_class", "string"},
+	{"suite", "string"},
+	{"warehouse", "string"},
+	{"schema_name", "string"},
+	{"run_number", "int"},
+	{"thread_id", "int"},
+	{"concurrency", "int"},
+	{"attempt", "int"},
+	{"reconnected", "bool"},
+	{"status", "string"},
+	{"error_class", "string"},
+	{"error_message", "string"},
+	{"duration_ms", "bigint"},
+	{"queue_delay_ms", "bigint"},
+	{"row_count", "bigint"},
+}
+
+// tableDialect - различия DDL и литералов между движками
+type tableDialect struct {
+	types map[string]string
+
+	// суффикс CREATE TABLE (формат хранения)
+	createSuffix string
+
+	// строки экранируются обратной косой чертой (hive, spark, impala), иначе удвоением кавычки
+	backslashEscapes bool
+
+	// предел строковой колонки в байтах, 0 - без ограничения
+	stringBytes int
+}
+
+func dialectFor(engine string) (tableDialect, error) {
+	switch engine {
+	case "trino":
+		return tableDialect{
+			types: map[string]string{"string": "VARCHAR", "timestamp": "TIMESTAMP(3)", "int": "INTEGER", "bigint": "BIGINT", "bool": "BOOLEAN"},
+		}, nil
+
+	case "vertica":
+		return tableDialect{
+			types:       map[string]string{"string": "VARCHAR(4000)", "timestamp": "TIMESTAMP", "int": "INTEGER", "bigint": "BIGINT", "bool": "BOOLEAN"},
+			stringBytes: 4000,
+		}, nil
+
+	case "impala", "hive", "spark":
+		return tableDialect{
+			types:            map[string]string{"string": "STRING", "timestamp": "TIMESTAMP", "int": "INT", "bigint": "BIGINT", "bool": "BOOLEAN"},
+			createSuffix:     " STORED AS PARQUET",
+			backslashEscapes: true,
+		}, nil
+
+	default:
+		return tableDialect{}, fmt.Errorf("таблица результатов не поддерживается для типа %s", engine)
+	}
+}
+
+func (d tableDialect) createTable(table string) string {
+	columns := make([]string, len(tableColumns))
+	for i, col := range tableColumns {
+		columns[i] = fmt.Sprintf("%s %s", col.name, d.types[col.kind])
+	}
+
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)%s", table, strings.Join(columns, ", "), d.createSuffix)
+}
+
+func (d tableDialect) insert(table, runID string, batch []BenchmarkResult) string {
+	names := make([]string, len(tableColumns))
+	for i, col := range tableColumns {
+		names[i] = col.name
+	}
+
+	rows := make([]string, len(batch))
+	for i, r := range batch {
+		values := []string{
+			d.str(runID),
+			d.timestamp(r.SaveResultTimestamp),
+			d.timestamp(r.StartTimestamp),
+			d.timestamp(r.EndTimestamp),
+			d.str(r.QueryID),
+			d.str(r.QueryClass),
+			d.str(r.Suite),
+			d.str(r.Warehouse),
+			d.str(r.Schema),
+			strconv.Itoa(r.RunNumber),
+			strconv.Itoa(r.ThreadID),
+			strconv.Itoa(r.Concurrency),
+			strconv.Itoa(r.Attempt),
+			strconv.FormatBool(r.Reconnected),
+			d.str(r.Status),
+			d.str(r.ErrorClass),
+			d.str(truncate(r.ErrorMsg, 2000)),
+			strconv.Itoa(r.DurationMs),
+			strconv.Itoa(r.QueueDelayMs),
+			strconv.Itoa(r.RowCount),
+		}
+
+		rows[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", table, strings.Join(names, ", "), strings.Join(rows, ", "))
+}
+
+func (d tableDialect) str(value string) string {
+	if d.stringBytes > 0 {
+		value = truncate(value, d.stringBytes)
+	}
+
+	if d.backslashEscapes {
+		value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	} else {
+		value = strings.ReplaceAll(value, "'", "''")
+	}
+
+	return "'" + value + "'"
+}
+
+func (d tableDialect) timestamp(t time.Time) string {
+	if t.IsZero() {
+		return "NULL"
+	}
+
+	return fmt.Sprintf("CAST('%s' AS %s)", t.UTC().Format("2006-01-02 15:04:05.000"), d.types["timestamp"])
+}
+
+// truncate обрезает строку до limit байт, не разрезая символ utf-8
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+
+	return value[:limit]
+}
+
+func (s *TableStorage) Open() error {
+	exec, engine, err := s.connect(s.cfg.Warehouse)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к хранилищу результатов %s: %w", s.cfg.Warehouse, err)
+	}
+
+	dialect, err := dialectFor(engine)
+	if err != nil {
+		exec.Close()
+		return err
+	}
+
+	s.exec = exec
+	s.dialect = dialect
+	s.lastFlush = time.Now()
+
+	// hive/spark выполняют USE схемы перед запросом
+	s.schema = "default"
+	if schema, _, ok := strings.Cut(s.cfg.Table, "."); ok {
+		s.schema = schema
+	}
+
+	if err := s.execute(dialect.createTable(s.cfg.Table)); err != nil {
+		exec.Close()
+		s.exec = nil
+		return fmt.Errorf("ошибка создания таблицы результатов %s: %w", s.cfg.Table, err)
+	}
+
+	log.Printf("результаты пишутся в таблицу %s на %s", s.cfg.Table, s.cfg.Warehouse)
+
+	s.wake = make(chan struct{}, 1)
+	s.done = make(chan struct{})
+
+	go s.writeLoop()
+
+	return nil
+}
+
+func (s *TableStorage) Save(result BenchmarkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("таблица результатов %s закрыта", s.cfg.Table)
+	}
+
+	s.batch = append(s.batch, result)
+
+	if len(s.batch) >= s.cfg.BatchSize {
+		s.signal()
+	}
+
+	return nil
+}
+
+// Flush будит горутину записи для неполной пачки не чаще flush_interval,
+// чтобы не плодить мелкие файлы, и возвращает ошибку прошлой фоновой вставки
+func (s *TableStorage) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed && len(s.batch) > 0 && time.Since(s.lastFlush) >= s.flushInterval {
+		s.signal()
+	}
+
+	err := s.err
+	s.err = nil
+
+	return err
+}
+
+// signal будит горутину записи; вызывается под mu, пока wake не закрыт
+func (s *TableStorage) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *TableStorage) Close() error {
+	s.mu.Lock()
+	if s.closed || s.exec == nil {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	close(s.wake)
+	s.mu.Unlock()
+
+	<-s.done
+
+	// последняя попытка для всего, что осталось в очереди
+	err := s.writePending()
+
+	s.exec.Close()
+
+	if err != nil {
+		s.mu.Lock()
+		lost := len(s.batch)
+		s.mu.Unlock()
+
+		return fmt.Errorf("таблица результатов %s: %d строк не записаны: %w", s.cfg.Table, lost, err)
+	}
+
+	return nil
+}
+
+func (s *TableStorage) Artifacts() []Artifact {
+	return nil
+}
+
+// writeLoop пишет полные пачки по сигналу, а неполную - по таймеру, как только
+// с прошлой вставки прошел flush_interval, даже если Flush давно не вызывался
+func (s *TableStorage) writeLoop() {
+	defer close(s.done)
+
+	var tick <-chan time.Time
+	if s.flushInterval > 0 {
+		ticker := time.NewTicker(max(s.flushInterval/4, 10*time.Millisecond))
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case _, ok := <-s.wake:
+			if !ok {
+				return
+			}
+
+		case <-tick:
+			s.mu.Lock()
+			due := len(s.batch) > 0 && time.Since(s.lastFlush) >= s.flushInterval
+			s.mu.Unlock()
+
+			if !due {
+				continue
+			}
+		}
+
+		if err := s.writePending(); err != nil {
+			log.Printf("WARNING: %v", err)
+
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+		}
+	}
+}
+
+// writePending забирает очередь и вставляет ее пачками по batch_size без блокировки
+// Save; при ошибке невставленные строки возвращаются в начало очереди
+func (s *TableStorage) writePending() error {
+	s.mu.Lock()
+	pending := s.batch
+	s.batch = nil
+	s.lastFlush = time.Now()
+	s.mu.Unlock()
+
+	size := max(s.cfg.BatchSize, 1)
+
+	for len(pending) > 0 {
+		n := min(size, len(pending))
+
+		if err := s.insertBatch(pending[:n]); err != nil {
+			s.mu.Lock()
+			s.batch = append(pending, s.batch...)
+			s.mu.Unlock()
+
+			return err
+		}
+
+		pending = pending[n:]
+	}
+
+	return nil
+}
+
+// insertBatch вставляет пачку с повторами
+func (s *TableStorage) insertBatch(batch []BenchmarkResult) error {
+	query := s.dialect.insert(s.cfg.Table, s.runID, batch)
+
+	var err error
+	for attempt := 0; attempt <= s.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			log.Printf("WARNING: таблица результатов %s: попытка %d/%d не удалась, повтор через %v: %v",
+				s.cfg.Table,
+				attempt,
+				s.cfg.Retries+1,
+				s.retryDelay,
+				err,
+			)
+			time.Sleep(s.retryDelay)
+		}
+
+		if err = s.execute(query); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("таблица результатов %s: пачка из %d строк не записана, строки остаются в очереди: %w", s.cfg.Table, len(batch), err)
+}
+
+func (s *TableStorage) execute(query string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	result, err := s.exec.Execute(ctx, query, s.schema)
+	if err != nil {
+		return err
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%s", result.Error)
+	}
+
+	return nil
+}
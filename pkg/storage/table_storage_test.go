@@ -0,0 +1,337 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"tpcds_benchmark/pkg/config"
+	"tpcds_benchmark/pkg/executor"
+	"unicode/utf8"
+)
+
+// fakeTableExecutor принимает CREATE сразу, а INSERT ждет release и падает, пока fail > 0
+type fakeTableExecutor struct {
+	mu       sync.Mutex
+	fail     int
+	attempts int
+	inserted int
+	release  chan struct{}
+}
+
+func (f *fakeTableExecutor) Execute(ctx context.Context, query string, schema string) (*executor.QueryResult, error) {
+	if !strings.HasPrefix(query, "INSERT") {
+		return &executor.QueryResult{Success: true}, nil
+	}
+
+	if f.release != nil {
+		<-f.release
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.attempts++
+	if f.fail > 0 {
+		f.fail--
+		return nil, errors.New("warehouse unavailable")
+	}
+
+	f.inserted += strings.Count(query, "), (") + 1
+	return &executor.QueryResult{Success: true}, nil
+}
+
+func (f *fakeTableExecutor) Name() string { return "fake" }
+
+func (f *fakeTableExecutor) Close() error { return nil }
+
+func (f *fakeTableExecutor) counts() (attempts, inserted int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.attempts, f.inserted
+}
+
+func openTableStorage(t *testing.T, exec *fakeTableExecutor, batchSize, retries int) *TableStorage {
+	t.Helper()
+
+	cfg := config.SinkConfig{
+		Warehouse:     "results",
+		Table:         "bench.results",
+		BatchSize:     batchSize,
+		Retries:       retries,
+		FlushInterval: "1h",
+		RetryDelay:    "1ms",
+		Timeout:       "5s",
+	}
+
+	s := NewTableStorage(cfg, "run.csv", func(name string) (executor.QueryExecutor, string, error) {
+		return exec, "trino", nil
+	})
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func tableResult(queryID string) BenchmarkResult {
+	return BenchmarkResult{QueryID: queryID, Warehouse: "trino", RunNumber: 1, Status: StatusSuccess}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTableDialectStr(t *testing.T) {
+	tests := []struct {
+		name      string
+		backslash bool
+		value     string
+		want      string
+	}{
+		{"plain", false, "q1", `'q1'`},
+		{"doubled quote", false, "it's", `'it''s'`},
+		{"backslash literal in standard mode", false, `C:\tmp`, `'C:\tmp'`},
+		{"escaped quote", true, "it's", `'it\'s'`},
+		{"escaped backslash", true, `C:\tmp`, `'C:\\tmp'`},
+		// обратная косая черта перед кавычкой не должна закрыть литерал
+		{"backslash before quote", true, `a\'b`, `'a\\\'b'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tableDialect{backslashEscapes: tt.backslash}
+			if got := d.str(tt.value); got != tt.want {
+				t.Errorf("str(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableDialectInsert(t *testing.T) {
+	trino, err := dialectFor("trino")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 678_000_000, time.UTC)
+	batch := []BenchmarkResult{
+		{
+			StartTimestamp: start,
+			EndTimestamp:   start.Add(1500 * time.Millisecond),
+			QueryID:        "q1",
+			Warehouse:      "trino",
+			RunNumber:      2,
+			Attempt:        1,
+			Reconnected:    true,
+			Status:         StatusError,
+			ErrorMsg:       "column 'x' not found",
+			DurationMs:     1500,
+		},
+		{QueryID: "q2", Status: StatusNotRun},
+	}
+
+	got := trino.insert("bench.results", "run", batch)
+
+	want := "INSERT INTO bench.results (run_id, save_result_timestamp, start_timestamp, end_timestamp, " +
+		"query_id, query_class, suite, warehouse, schema_name, run_number, thread_id, concurrency, attempt, " +
+		"reconnected, status, error_class, error_message, duration_ms, queue_delay_ms, row_count) VALUES " +
+		"('run', NULL, CAST('2026-01-02 03:04:05.678' AS TIMESTAMP(3)), CAST('2026-01-02 03:04:07.178' AS TIMESTAMP(3)), " +
+		"'q1', '', '', 'trino', '', 2, 0, 0, 1, true, 'error', '', 'column ''x'' not found', 1500, 0, 0), " +
+		"('run', NULL, NULL, NULL, 'q2', '', '', '', '', 0, 0, 0, 0, false, 'not_run', '', '', 0, 0, 0)"
+
+	if got != want {
+		t.Errorf("insert:\n got: %s\nwant: %s", got, want)
+	}
+
+	hive, err := dialectFor("hive")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = hive.insert("bench.results", "run", batch[:1])
+	if !strings.Contains(got, `'column \'x\' not found'`) || !strings.Contains(got, "AS TIMESTAMP)") {
+		t.Errorf("hive insert uses wrong escaping or types: %s", got)
+	}
+
+	if ddl := hive.createTable("bench.results"); !strings.HasSuffix(ddl, "row_count BIGINT) STORED AS PARQUET") {
+		t.Errorf("hive create table: %s", ddl)
+	}
+}
+
+func TestTruncateKeepsValidUTF8(t *testing.T) {
+	// 2 байта на кириллическую букву: граница 2000 байт приходится на середину символа
+	msg := "Ы" + strings.Repeat("ошибка выполнения ", 200)
+
+	got := truncate(msg, 2000)
+	if !utf8.ValidString(got) || len(got) > 2000 || len(got) < 1998 {
+		t.Errorf("truncate: %d bytes, valid utf-8 %v", len(got), utf8.ValidString(got))
+	}
+
+	// нечетный предел всегда попадает в середину двухбайтной буквы
+	if got := truncate(strings.Repeat("я", 1500), 1999); len(got) != 1998 || !utf8.ValidString(got) {
+		t.Errorf("truncate at odd limit: %d bytes, valid utf-8 %v", len(got), utf8.ValidString(got))
+	}
+
+	if got := truncate("short", 2000); got != "short" {
+		t.Errorf("short value changed: %q", got)
+	}
+
+	for _, engine := range []string{"trino", "vertica", "hive"} {
+		d, err := dialectFor(engine)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		query := d.insert("bench.results", "run", []BenchmarkResult{{QueryID: "q1", ErrorMsg: msg}})
+		if !utf8.ValidString(query) {
+			t.Errorf("%s: insert is not valid utf-8", engine)
+		}
+	}
+
+	// vertica: VARCHAR(4000) ограничен в байтах
+	vertica, err := dialectFor("vertica")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	long := strings.Repeat("щ", 3000)
+	literal := vertica.str(long)
+	if value := strings.Trim(literal, "'"); len(value) > 4000 || !utf8.ValidString(value) {
+		t.Errorf("vertica literal: %d bytes, valid utf-8 %v", len(value), utf8.ValidString(value))
+	}
+}
+
+func TestTableSaveDoesNotBlockOnSlowInsert(t *testing.T) {
+	exec := &fakeTableExecutor{release: make(chan struct{})}
+	s := openTableStorage(t, exec, 1, 0)
+
+	// вставка первой пачки висит, а Save и Flush продолжают возвращаться сразу
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for _, id := range []string{"q1", "q2", "q3"} {
+			if err := s.Save(tableResult(id)); err != nil {
+				t.Error(err)
+			}
+			if err := s.Flush(); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Save blocked while insert was in progress")
+	}
+
+	close(exec.release)
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, inserted := exec.counts(); inserted != 3 {
+		t.Errorf("expected 3 rows inserted, got %d", inserted)
+	}
+}
+
+func TestTablePartialBatchFlushedOnTimer(t *testing.T) {
+	exec := &fakeTableExecutor{}
+
+	s := NewTableStorage(config.SinkConfig{
+		Warehouse:     "results",
+		Table:         "bench.results",
+		BatchSize:     100,
+		FlushInterval: "50ms",
+		RetryDelay:    "1ms",
+		Timeout:       "5s",
+	}, "run.csv", func(name string) (executor.QueryExecutor, string, error) {
+		return exec, "trino", nil
+	})
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.Save(tableResult("q1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flush не вызывается: долгий запрос или редкие поступления open-loop
+	waitFor(t, "timer flush", func() bool {
+		_, inserted := exec.counts()
+		return inserted == 1
+	})
+}
+
+func TestTableFailedBatchIsRequeued(t *testing.T) {
+	exec := &fakeTableExecutor{fail: 2}
+	s := openTableStorage(t, exec, 2, 1)
+
+	for _, id := range []string{"q1", "q2"} {
+		if err := s.Save(tableResult(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// обе попытки (retries: 1) не удались, ошибка приходит из следующего Flush
+	waitFor(t, "failed insert", func() bool {
+		attempts, _ := exec.counts()
+		return attempts == 2
+	})
+
+	waitFor(t, "error from flush", func() bool {
+		return s.Flush() != nil
+	})
+
+	if err := s.Save(tableResult("q3")); err != nil {
+		t.Fatal(err)
+	}
+
+	// пачка не потеряна: все три строки уходят при закрытии
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, inserted := exec.counts(); inserted != 3 {
+		t.Errorf("expected 3 rows inserted after requeue, got %d", inserted)
+	}
+}
+
+func TestTableCloseReportsLostRows(t *testing.T) {
+	exec := &fakeTableExecutor{fail: 100}
+	s := openTableStorage(t, exec, 10, 0)
+
+	if err := s.Save(tableResult("q1")); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.Close()
+	if err == nil || !strings.Contains(err.Error(), "1 строк не записаны") {
+		t.Fatalf("expected lost rows error, got %v", err)
+	}
+
+	if err := s.Save(tableResult("q2")); err == nil {
+		t.Error("Save after Close should fail")
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
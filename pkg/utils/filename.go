@@ -8,11 +8,18 @@ import (
 
 func GetFileName(cfg *config.Config) string {
 	now := time.Now()
+
+	suite := ""
+	if cfg.Suite != "" {
+		suite = cfg.Suite + "_suite_"
+	}
+
 	filename := fmt.Sprintf(
-		"%d_runs_%d_concurrency_%s_schema_%s%s",
+		"%d_runs_%d_concurrency_%s_schema_%s%s%s",
 		cfg.Runs,
 		cfg.Concurrency,
 		cfg.Schema,
+		suite,
 		now.Format("2006-01-02_15_04_05.000"),
 		".csv",
 	)
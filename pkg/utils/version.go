@@ -0,0 +1,44 @@
+package utils
+
+import "runtime/debug"
+
+// GitRevision - коммит, из которого собран бинарник; пусто, если сборка без vcs
+func GitRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	revision, modified := "", false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+
+	return revision
+}
+
+// Version задается при сборке: -ldflags "-X tpcds_benchmark/pkg/utils.Version=..."
+var Version = ""
+
+// ToolVersion - версия бенчмарка: из ldflags или версия модуля из build info
+func ToolVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	return info.Main.Version
+}